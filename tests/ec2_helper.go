@@ -1,22 +1,35 @@
 package tests
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/stretchr/testify/assert"
 )
 
 // Vpc struct containing elements returned from a VPC module.
-// This provides a convenient way of consolidating our VPC attributes when calling helper functions
+// This provides a convenient way of consolidating our VPC attributes when calling helper functions.
+// VpcID and VpcCidr are always validated. Empty strings, nil slices and nil pointers in the other fields are not validated
 type Vpc struct {
-	VpcID   string
-	VpcCidr string
+	VpcID              string
+	VpcCidr            string
+	SecondaryCidrs     []string
+	Ipv6Cidrs          []string
+	InstanceTenancy    string
+	DhcpOptionsID      string
+	EnableDNSSupport   *bool
+	EnableDNSHostnames *bool
 }
 
 // ValidateVpc validate a VPC via attributes passed in using the Vpc struct
@@ -40,7 +53,87 @@ func ValidateVpc(t *testing.T, svc *ec2.EC2, vpc Vpc, verboseOutput bool) {
 		fmt.Println(describeVpcResult.String())
 	}
 
-	assert.Equal(t, vpc.VpcCidr, *describeVpcResult.Vpcs[0].CidrBlock)
+	if !assert.Len(t, describeVpcResult.Vpcs, 1, "VPC %s not found", vpc.VpcID) {
+		return
+	}
+
+	result := describeVpcResult.Vpcs[0]
+
+	assert.Equal(t, vpc.VpcID, aws.StringValue(result.VpcId))
+
+	assert.Equal(t, vpc.VpcCidr, aws.StringValue(result.CidrBlock))
+
+	if vpc.SecondaryCidrs != nil {
+		secondaryCidrs := []string{}
+
+		for _, association := range result.CidrBlockAssociationSet {
+			if aws.StringValue(association.CidrBlock) != aws.StringValue(result.CidrBlock) && aws.StringValue(association.CidrBlockState.State) == ec2.VpcCidrBlockStateCodeAssociated {
+				secondaryCidrs = append(secondaryCidrs, aws.StringValue(association.CidrBlock))
+			}
+		}
+
+		assert.ElementsMatch(t, vpc.SecondaryCidrs, secondaryCidrs, "secondary CIDRs of VPC %s", vpc.VpcID)
+	}
+
+	if vpc.Ipv6Cidrs != nil {
+		ipv6Cidrs := []string{}
+
+		for _, association := range result.Ipv6CidrBlockAssociationSet {
+			if aws.StringValue(association.Ipv6CidrBlockState.State) == ec2.VpcCidrBlockStateCodeAssociated {
+				ipv6Cidrs = append(ipv6Cidrs, aws.StringValue(association.Ipv6CidrBlock))
+			}
+		}
+
+		assert.ElementsMatch(t, vpc.Ipv6Cidrs, ipv6Cidrs, "IPv6 CIDRs of VPC %s", vpc.VpcID)
+	}
+
+	if vpc.InstanceTenancy != "" {
+		assert.Equal(t, vpc.InstanceTenancy, aws.StringValue(result.InstanceTenancy))
+	}
+
+	if vpc.DhcpOptionsID != "" {
+		assert.Equal(t, vpc.DhcpOptionsID, aws.StringValue(result.DhcpOptionsId))
+	}
+
+	if vpc.EnableDNSSupport != nil {
+		assert.Equal(t, *vpc.EnableDNSSupport, getVpcAttribute(t, svc, vpc.VpcID, ec2.VpcAttributeNameEnableDnsSupport, verboseOutput), "enableDnsSupport of VPC %s", vpc.VpcID)
+	}
+
+	if vpc.EnableDNSHostnames != nil {
+		assert.Equal(t, *vpc.EnableDNSHostnames, getVpcAttribute(t, svc, vpc.VpcID, ec2.VpcAttributeNameEnableDnsHostnames, verboseOutput), "enableDnsHostnames of VPC %s", vpc.VpcID)
+	}
+}
+
+// getVpcAttribute gets a boolean attribute of a VPC, failing the test if it cannot be read
+func getVpcAttribute(t *testing.T, svc *ec2.EC2, vpcID string, attribute string, verboseOutput bool) bool {
+	t.Helper()
+
+	describeVpcAttributeResult, err := svc.DescribeVpcAttribute(
+		&ec2.DescribeVpcAttributeInput{
+			VpcId:     aws.String(vpcID),
+			Attribute: aws.String(attribute),
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return false
+	}
+
+	if verboseOutput {
+		fmt.Println(describeVpcAttributeResult.String())
+	}
+
+	switch attribute {
+	case ec2.VpcAttributeNameEnableDnsSupport:
+		return describeVpcAttributeResult.EnableDnsSupport != nil && aws.BoolValue(describeVpcAttributeResult.EnableDnsSupport.Value)
+	case ec2.VpcAttributeNameEnableDnsHostnames:
+		return describeVpcAttributeResult.EnableDnsHostnames != nil && aws.BoolValue(describeVpcAttributeResult.EnableDnsHostnames.Value)
+	default:
+		return describeVpcAttributeResult.EnableNetworkAddressUsageMetrics != nil && aws.BoolValue(describeVpcAttributeResult.EnableNetworkAddressUsageMetrics.Value)
+	}
 }
 
 // ValidateTgwConsumer helper function to validate transit gateway vpc associations
@@ -68,6 +161,8 @@ func ValidateTgwConsumer(t *testing.T, svc *ec2.EC2, verboseOutput bool, tgwAtta
 }
 
 // ValidateVPC gets vpc and validates its info
+//
+// Deprecated: ValidateVPC asserts on whichever VPC AWS returns first. Use ValidateVpc or ValidateSingleVPC to validate a VPC by ID.
 func ValidateVPC(t *testing.T, svc *ec2.EC2, isDefault bool, cidrBlockState string, instanceTenancy string, ownerID string, state string, tagValues []string, verboseOutput bool) {
 	t.Helper()
 
@@ -163,6 +258,10 @@ func ValidateSingleVPC(t *testing.T, svc *ec2.EC2, vpcID string, isDefault bool,
 }
 
 // ValidateFlowLog gets FlowLog and validates its info
+//
+// Deprecated: ValidateFlowLog reads only the first page of flow logs in the account and checks every flow log of the VPC
+// against one set of values, so it gives wrong results when a VPC has more than one flow log and passes when none match.
+// Use ValidateFlowLogs instead.
 func ValidateFlowLog(t *testing.T, svc *ec2.EC2, vpcID string, deliverLogsPermissionArn string, deliverLogsStatus string, flowLogStatus string, logDestination string, logDestinationType string, logFormat string, trafficType string, verboseOutput bool) {
 	t.Helper()
 
@@ -210,6 +309,8 @@ func ValidateFlowLog(t *testing.T, svc *ec2.EC2, vpcID string, deliverLogsPermis
 }
 
 // ValidateInternetGateway gets InternetGateway and validates its info
+//
+// Deprecated: ValidateInternetGateway checks whichever internet gateway AWS returns first. Use ValidateVpcInternetGateway to validate the gateway attached to a VPC.
 func ValidateInternetGateway(t *testing.T, svc *ec2.EC2, state string, ownerID string, tagValues []string, verboseOutput bool) {
 	t.Helper()
 
@@ -253,9 +354,17 @@ func ValidateInternetGateway(t *testing.T, svc *ec2.EC2, state string, ownerID s
 }
 
 // ValidateRouteTables gets Route Tables and validates its info
+//
+// Deprecated: ValidateRouteTables relies on default-rtb, public-rtb and private-rtb tag naming, only counts routes and
+// requires at least eight tag values. Use ValidateVpcRouteTables to validate each route.
 func ValidateRouteTables(t *testing.T, svc *ec2.EC2, vpcID string, ownerID string, tagValues []string, verboseOutput bool) {
 	t.Helper()
 
+	// the eighth tag value is dropped below, so fewer values cannot be handled
+	if !assert.GreaterOrEqual(t, len(tagValues), 8, "ValidateRouteTables requires at least eight tag values") {
+		return
+	}
+
 	describeRouteTablesInput := &ec2.DescribeRouteTablesInput{
 		Filters: []*ec2.Filter{
 			{
@@ -288,6 +397,9 @@ func ValidateRouteTables(t *testing.T, svc *ec2.EC2, vpcID string, ownerID strin
 		fmt.Println(describeRouteTablesResult.String())
 	}
 
+	// work on a copy so the caller's tagValues are left untouched
+	tagValues = append([]string{}, tagValues...)
+
 	// Remove the element at index i from a.
 	tagValues[7] = tagValues[len(tagValues)-1] // Copy last element to index i.
 	tagValues[len(tagValues)-1] = ""           // Erase last element (write zero value).
@@ -348,6 +460,8 @@ func ValidateRouteTables(t *testing.T, svc *ec2.EC2, vpcID string, ownerID strin
 }
 
 // ValidateSubnet gets Subnet and validates its info
+//
+// Deprecated: ValidateSubnet matches subnets across the whole account by tag substrings. Use ValidateVpcSubnets instead.
 func ValidateSubnet(t *testing.T, svc *ec2.EC2, state string, ownerID string, tagValues []string, verboseOutput bool) {
 	t.Helper()
 
@@ -417,6 +531,8 @@ func ValidateSubnet(t *testing.T, svc *ec2.EC2, state string, ownerID string, ta
 }
 
 // ValidateNatGateway gets NatGateway and validates its info
+//
+// Deprecated: ValidateNatGateway matches NAT gateways across the whole account by tag substrings. Use ValidateVpcNatGateways instead.
 func ValidateNatGateway(t *testing.T, svc *ec2.EC2, state string, tagValues []string, verboseOutput bool) {
 	t.Helper()
 
@@ -473,6 +589,8 @@ func ValidateNatGateway(t *testing.T, svc *ec2.EC2, state string, tagValues []st
 }
 
 // ValidateNetworkACLs gets NetworkAcl and validates its info
+//
+// Deprecated: ValidateNetworkACLs only counts the entries of the network ACL. Use ValidateNetworkACLEntries to validate the entries themselves.
 func ValidateNetworkACLs(t *testing.T, svc *ec2.EC2, naclName string, naclRules int, verboseOutput bool) {
 	t.Helper()
 
@@ -514,7 +632,9 @@ func ValidateNetworkACLs(t *testing.T, svc *ec2.EC2, naclName string, naclRules
 	assert.Equal(t, naclRules, len(describeNetworkAclsResult.NetworkAcls[0].Entries))
 }
 
-// ValidateVpcEndpoints gets NetworkAcl and validates its info
+// ValidateVpcEndpoints gets VpcEndpoint and validates its info
+//
+// Deprecated: ValidateVpcEndpoints only checks the first endpoint found and does not validate its policy, subnets or route tables. Use ValidateVpcEndpoint instead.
 func ValidateVpcEndpoints(t *testing.T, svc *ec2.EC2, serviceName string, vpcID string, ownerID string, state string, privateDNSEnabled bool, securityGroups []string, vpcEndpointType string, verboseOutput bool) {
 	t.Helper()
 
@@ -571,6 +691,8 @@ func ValidateVpcEndpoints(t *testing.T, svc *ec2.EC2, serviceName string, vpcID
 }
 
 // ValidateSecurityGroup gets security group by name and vpcID and validates its info
+//
+// Deprecated: ValidateSecurityGroup only counts the rules of the group. Use ValidateSecurityGroupRules to validate the rules themselves.
 func ValidateSecurityGroup(t *testing.T, svc *ec2.EC2, vpcID string, groupName string, numIngressRules int, numEgressRules int, verboseOutput bool) {
 	t.Helper()
 
@@ -620,6 +742,8 @@ func ValidateSecurityGroup(t *testing.T, svc *ec2.EC2, vpcID string, groupName s
 }
 
 // ValidateTransitGateways gets NetworkAcl and validates its info
+//
+// Deprecated: ValidateTransitGateways only checks whichever transit gateway AWS returns first. Use ValidateTransitGateway to validate a transit gateway by ID.
 func ValidateTransitGateways(t *testing.T, svc *ec2.EC2, verboseOutput bool) {
 	t.Helper()
 
@@ -653,6 +777,8 @@ func ValidateTransitGateways(t *testing.T, svc *ec2.EC2, verboseOutput bool) {
 }
 
 // ValidateTransitGatewayAttachments gets NetworkAcl and validates its info
+//
+// Deprecated: ValidateTransitGatewayAttachments only checks whichever attachment AWS returns first. Use ValidateTransitGatewayVpcAttachment to validate an attachment by ID.
 func ValidateTransitGatewayAttachments(t *testing.T, svc *ec2.EC2, verboseOutput bool) {
 	t.Helper()
 
@@ -684,3 +810,1742 @@ func ValidateTransitGatewayAttachments(t *testing.T, svc *ec2.EC2, verboseOutput
 	assert.NotEmpty(t, describeTransitGatewayAttachmentsResult.TransitGatewayAttachments)
 	assert.Equal(t, "available", *describeTransitGatewayAttachmentsResult.TransitGatewayAttachments[0].State)
 }
+
+// Subnet struct describing a subnet expected in a VPC.
+// AvailabilityZone may be either the zone name (us-east-1a) or the zone ID (use1-az1).
+// A subnet is public when its route table has a route to an internet gateway
+type Subnet struct {
+	Name                string
+	Cidr                string
+	AvailabilityZone    string
+	Public              bool
+	MapPublicIPOnLaunch bool
+	Ipv6Cidr            string
+}
+
+// ValidateVpcSubnets validates that a VPC contains exactly the expected subnets, that each subnet CIDR
+// is within the VPC CIDR blocks and that no two subnets overlap
+func ValidateVpcSubnets(t *testing.T, svc *ec2.EC2, vpcID string, expectedSubnets []Subnet, verboseOutput bool) {
+	t.Helper()
+
+	vpcFilter := []*ec2.Filter{
+		{
+			Name:   aws.String("vpc-id"),
+			Values: []*string{aws.String(vpcID)},
+		},
+	}
+
+	describeVpcResult, err := svc.DescribeVpcs(
+		&ec2.DescribeVpcsInput{
+			VpcIds: []*string{aws.String(vpcID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if !assert.Len(t, describeVpcResult.Vpcs, 1, "VPC %s not found", vpcID) {
+		return
+	}
+
+	subnets := []*ec2.Subnet{}
+
+	err = svc.DescribeSubnetsPages(
+		&ec2.DescribeSubnetsInput{Filters: vpcFilter},
+		func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+			subnets = append(subnets, page.Subnets...)
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	routeTables := []*ec2.RouteTable{}
+
+	err = svc.DescribeRouteTablesPages(
+		&ec2.DescribeRouteTablesInput{Filters: vpcFilter},
+		func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+			routeTables = append(routeTables, page.RouteTables...)
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeVpcResult.String())
+		fmt.Println(subnets)
+		fmt.Println(routeTables)
+	}
+
+	vpcCidrs := []*net.IPNet{}
+
+	for _, association := range describeVpcResult.Vpcs[0].CidrBlockAssociationSet {
+		if aws.StringValue(association.CidrBlockState.State) != ec2.VpcCidrBlockStateCodeAssociated {
+			continue
+		}
+
+		if _, cidr, err := net.ParseCIDR(aws.StringValue(association.CidrBlock)); err == nil {
+			vpcCidrs = append(vpcCidrs, cidr)
+		}
+	}
+
+	subnetsByCidr := map[string]*ec2.Subnet{}
+	parsedCidrs := map[string]*net.IPNet{}
+
+	for _, subnet := range subnets {
+		cidrBlock := aws.StringValue(subnet.CidrBlock)
+		subnetsByCidr[cidrBlock] = subnet
+
+		_, cidr, err := net.ParseCIDR(cidrBlock)
+		if err != nil {
+			assert.Fail(t, "invalid subnet CIDR", "subnet %s has CIDR %s: %s", aws.StringValue(subnet.SubnetId), cidrBlock, err.Error())
+
+			continue
+		}
+
+		parsedCidrs[cidrBlock] = cidr
+
+		if !cidrWithinAny(cidr, vpcCidrs) {
+			assert.Fail(t, "subnet CIDR outside VPC", "subnet %s CIDR %s is not within the CIDR blocks of VPC %s", aws.StringValue(subnet.SubnetId), cidrBlock, vpcID)
+		}
+	}
+
+	for i := 0; i < len(subnets); i++ {
+		for x := i + 1; x < len(subnets); x++ {
+			first, second := parsedCidrs[aws.StringValue(subnets[i].CidrBlock)], parsedCidrs[aws.StringValue(subnets[x].CidrBlock)]
+			if first != nil && second != nil && cidrsOverlap(first, second) {
+				assert.Fail(t, "overlapping subnets", "subnet %s (%s) overlaps subnet %s (%s)", aws.StringValue(subnets[i].SubnetId), first, aws.StringValue(subnets[x].SubnetId), second)
+			}
+		}
+	}
+
+	expectedCidrs := map[string]bool{}
+
+	for _, expected := range expectedSubnets {
+		expectedCidrs[expected.Cidr] = true
+
+		subnet, ok := subnetsByCidr[expected.Cidr]
+		if !ok {
+			assert.Fail(t, "missing subnet", "VPC %s has no subnet %s with CIDR %s", vpcID, expected.Name, expected.Cidr)
+
+			continue
+		}
+
+		subnetID := aws.StringValue(subnet.SubnetId)
+
+		assert.Equal(t, expected.Name, ec2TagValue(subnet.Tags, "Name"), "name of subnet %s", subnetID)
+		assert.Contains(t, []string{aws.StringValue(subnet.AvailabilityZone), aws.StringValue(subnet.AvailabilityZoneId)}, expected.AvailabilityZone, "availability zone of subnet %s", subnetID)
+		assert.Equal(t, expected.MapPublicIPOnLaunch, aws.BoolValue(subnet.MapPublicIpOnLaunch), "MapPublicIpOnLaunch of subnet %s", subnetID)
+		assert.Equal(t, expected.Public, routeTableHasInternetGatewayRoute(subnetRouteTable(routeTables, subnetID)), "public routing of subnet %s", subnetID)
+
+		ipv6Cidr := ""
+
+		for _, association := range subnet.Ipv6CidrBlockAssociationSet {
+			if aws.StringValue(association.Ipv6CidrBlockState.State) == ec2.SubnetCidrBlockStateCodeAssociated {
+				ipv6Cidr = aws.StringValue(association.Ipv6CidrBlock)
+			}
+		}
+
+		assert.Equal(t, expected.Ipv6Cidr, ipv6Cidr, "IPv6 CIDR of subnet %s", subnetID)
+	}
+
+	for cidrBlock, subnet := range subnetsByCidr {
+		if !expectedCidrs[cidrBlock] {
+			assert.Fail(t, "unexpected subnet", "VPC %s has unexpected subnet %s (%s) with CIDR %s", vpcID, aws.StringValue(subnet.SubnetId), ec2TagValue(subnet.Tags, "Name"), cidrBlock)
+		}
+	}
+}
+
+// subnetRouteTable returns the route table explicitly associated with a subnet, falling back to the main route table of the VPC
+func subnetRouteTable(routeTables []*ec2.RouteTable, subnetID string) *ec2.RouteTable {
+	var mainRouteTable *ec2.RouteTable
+
+	for _, routeTable := range routeTables {
+		for _, association := range routeTable.Associations {
+			if aws.StringValue(association.SubnetId) == subnetID {
+				return routeTable
+			}
+
+			if aws.BoolValue(association.Main) {
+				mainRouteTable = routeTable
+			}
+		}
+	}
+
+	return mainRouteTable
+}
+
+// routeTableHasInternetGatewayRoute checks whether a route table has an active route to an internet gateway
+func routeTableHasInternetGatewayRoute(routeTable *ec2.RouteTable) bool {
+	if routeTable == nil {
+		return false
+	}
+
+	for _, route := range routeTable.Routes {
+		if strings.HasPrefix(aws.StringValue(route.GatewayId), "igw-") && aws.StringValue(route.State) == ec2.RouteStateActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ec2TagValue returns the value of the tag with the given key, or an empty string when the tag is not set
+func ec2TagValue(tags []*ec2.Tag, key string) string {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == key {
+			return aws.StringValue(tag.Value)
+		}
+	}
+
+	return ""
+}
+
+// cidrWithinAny checks whether a CIDR is fully contained by one of the given CIDRs
+func cidrWithinAny(cidr *net.IPNet, parents []*net.IPNet) bool {
+	cidrOnes, _ := cidr.Mask.Size()
+
+	for _, parent := range parents {
+		parentOnes, _ := parent.Mask.Size()
+		if parent.Contains(cidr.IP) && cidrOnes >= parentOnes {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cidrsOverlap checks whether two CIDRs share any address
+func cidrsOverlap(first *net.IPNet, second *net.IPNet) bool {
+	return first.Contains(second.IP) || second.Contains(first.IP)
+}
+
+// Route struct describing a route expected in a route table.
+// Destination is an IPv4 CIDR, an IPv6 CIDR or a prefix list ID.
+// Target is the ID of the gateway, NAT gateway, transit gateway, VPC endpoint, peering connection or network interface, or local for the VPC local route
+type Route struct {
+	Destination string
+	Target      string
+}
+
+// RouteTable struct describing a route table expected in a VPC.
+// The route table is looked up by RouteTableID when it is set, otherwise by its Name tag, so one of them must be set.
+// Routes must list every route in the table, including the local route
+type RouteTable struct {
+	RouteTableID string
+	Name         string
+	Main         bool
+	SubnetIDs    []string
+	Routes       []Route
+}
+
+// ValidateVpcRouteTables validates the subnet associations, main flag and exact routes of each expected route table in a VPC
+func ValidateVpcRouteTables(t *testing.T, svc *ec2.EC2, vpcID string, expectedRouteTables []RouteTable, verboseOutput bool) {
+	t.Helper()
+
+	routeTables := []*ec2.RouteTable{}
+
+	err := svc.DescribeRouteTablesPages(
+		&ec2.DescribeRouteTablesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []*string{aws.String(vpcID)},
+				},
+			},
+		},
+		func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+			routeTables = append(routeTables, page.RouteTables...)
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(routeTables)
+	}
+
+	for _, expected := range expectedRouteTables {
+		if expected.RouteTableID == "" && expected.Name == "" {
+			assert.Fail(t, "route table not identified", "an expected route table in VPC %s has neither a RouteTableID nor a Name", vpcID)
+
+			continue
+		}
+
+		matches := []*ec2.RouteTable{}
+
+		for _, routeTable := range routeTables {
+			if (expected.RouteTableID != "" && aws.StringValue(routeTable.RouteTableId) == expected.RouteTableID) ||
+				(expected.RouteTableID == "" && ec2TagValue(routeTable.Tags, "Name") == expected.Name) {
+				matches = append(matches, routeTable)
+			}
+		}
+
+		if len(matches) != 1 {
+			assert.Fail(t, "route table not found", "expected exactly one route table %s%s in VPC %s, found %d", expected.RouteTableID, expected.Name, vpcID, len(matches))
+
+			continue
+		}
+
+		routeTable := matches[0]
+		routeTableID := aws.StringValue(routeTable.RouteTableId)
+
+		isMain := false
+		subnetIDs := []string{}
+
+		for _, association := range routeTable.Associations {
+			if aws.BoolValue(association.Main) {
+				isMain = true
+			}
+
+			if association.SubnetId != nil && aws.StringValue(association.AssociationState.State) == ec2.RouteTableAssociationStateCodeAssociated {
+				subnetIDs = append(subnetIDs, aws.StringValue(association.SubnetId))
+			}
+		}
+
+		assert.Equal(t, expected.Main, isMain, "main flag of route table %s", routeTableID)
+		assert.ElementsMatch(t, expected.SubnetIDs, subnetIDs, "subnet associations of route table %s", routeTableID)
+
+		actualRoutes := []Route{}
+
+		for _, route := range routeTable.Routes {
+			actualRoute := routeFromEc2Route(route)
+			actualRoutes = append(actualRoutes, actualRoute)
+
+			if aws.StringValue(route.State) == ec2.RouteStateBlackhole {
+				assert.Fail(t, "blackhole route", "route table %s route %s -> %s is a blackhole", routeTableID, actualRoute.Destination, actualRoute.Target)
+			}
+		}
+
+		missingRoutes := []string{}
+
+		for _, route := range expected.Routes {
+			if !containsRoute(actualRoutes, route) {
+				missingRoutes = append(missingRoutes, route.Destination+" -> "+route.Target)
+			}
+		}
+
+		unexpectedRoutes := []string{}
+
+		for _, route := range actualRoutes {
+			if !containsRoute(expected.Routes, route) {
+				unexpectedRoutes = append(unexpectedRoutes, route.Destination+" -> "+route.Target)
+			}
+		}
+
+		assert.Empty(t, missingRoutes, "route table %s is missing routes", routeTableID)
+		assert.Empty(t, unexpectedRoutes, "route table %s has unexpected routes", routeTableID)
+	}
+}
+
+// routeFromEc2Route flattens an EC2 route into its destination and target
+func routeFromEc2Route(route *ec2.Route) Route {
+	destination := aws.StringValue(route.DestinationCidrBlock)
+
+	switch {
+	case route.DestinationIpv6CidrBlock != nil:
+		destination = aws.StringValue(route.DestinationIpv6CidrBlock)
+	case route.DestinationPrefixListId != nil:
+		destination = aws.StringValue(route.DestinationPrefixListId)
+	}
+
+	target := ""
+
+	for _, id := range []*string{
+		route.GatewayId,
+		route.NatGatewayId,
+		route.TransitGatewayId,
+		route.VpcPeeringConnectionId,
+		route.EgressOnlyInternetGatewayId,
+		route.CarrierGatewayId,
+		route.LocalGatewayId,
+		route.CoreNetworkArn,
+		route.NetworkInterfaceId,
+		route.InstanceId,
+	} {
+		if id != nil {
+			target = aws.StringValue(id)
+
+			break
+		}
+	}
+
+	return Route{
+		Destination: destination,
+		Target:      target,
+	}
+}
+
+// containsRoute checks whether a route is present in a list of routes
+func containsRoute(routes []Route, route Route) bool {
+	for _, r := range routes {
+		if r == route {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SecurityGroupRule struct describing a rule expected on a security group.
+// Protocol is tcp, udp, icmp, -1 or an IP protocol number. Set exactly one of CidrIpv4, CidrIpv6, PrefixListID or ReferencedGroup.
+// ReferencedGroup may be a security group ID or the name of a security group in the same VPC
+type SecurityGroupRule struct {
+	Egress          bool
+	Protocol        string
+	FromPort        int64
+	ToPort          int64
+	CidrIpv4        string
+	CidrIpv6        string
+	PrefixListID    string
+	ReferencedGroup string
+	Description     string
+}
+
+// ValidateSecurityGroupRules validates that a security group has exactly the expected ingress and egress rules
+func ValidateSecurityGroupRules(t *testing.T, svc *ec2.EC2, groupID string, expectedRules []SecurityGroupRule, verboseOutput bool) {
+	t.Helper()
+
+	describeSecurityGroupsResult, err := svc.DescribeSecurityGroups(
+		&ec2.DescribeSecurityGroupsInput{
+			GroupIds: []*string{aws.String(groupID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if !assert.Len(t, describeSecurityGroupsResult.SecurityGroups, 1, "security group %s not found", groupID) {
+		return
+	}
+
+	vpcID := aws.StringValue(describeSecurityGroupsResult.SecurityGroups[0].VpcId)
+
+	securityGroupRules := getSecurityGroupRules(t, svc, groupID, verboseOutput)
+	if securityGroupRules == nil {
+		return
+	}
+
+	actualRules := []SecurityGroupRule{}
+	for _, rule := range securityGroupRules {
+		actualRules = append(actualRules, normalizeSecurityGroupRule(securityGroupRuleFromEc2Rule(rule)))
+	}
+
+	normalizedExpectedRules := []SecurityGroupRule{}
+
+	for _, rule := range expectedRules {
+		if rule.ReferencedGroup != "" && !strings.HasPrefix(rule.ReferencedGroup, "sg-") {
+			rule.ReferencedGroup = resolveSecurityGroupName(t, svc, vpcID, rule.ReferencedGroup)
+		}
+
+		normalizedExpectedRules = append(normalizedExpectedRules, normalizeSecurityGroupRule(rule))
+	}
+
+	missingRules := []SecurityGroupRule{}
+
+	for _, rule := range normalizedExpectedRules {
+		if !containsSecurityGroupRule(actualRules, rule) {
+			missingRules = append(missingRules, rule)
+		}
+	}
+
+	unexpectedRules := []SecurityGroupRule{}
+
+	for _, rule := range actualRules {
+		if !containsSecurityGroupRule(normalizedExpectedRules, rule) {
+			unexpectedRules = append(unexpectedRules, rule)
+		}
+	}
+
+	assert.Empty(t, missingRules, "security group %s is missing rules", groupID)
+	assert.Empty(t, unexpectedRules, "security group %s has unexpected rules", groupID)
+}
+
+// ValidateSecurityGroupDoesNotAllow validates that no ingress rule of a security group allows traffic from any address of
+// a CIDR, such as 0.0.0.0/0 or ::/0, on the given protocol and port. A rule fails the test when its CIDR overlaps the
+// forbidden CIDR at all, since even a narrower rule exposes part of the range
+func ValidateSecurityGroupDoesNotAllow(t *testing.T, svc *ec2.EC2, groupID string, cidr string, protocol string, port int64, verboseOutput bool) {
+	t.Helper()
+
+	_, forbiddenCidr, err := net.ParseCIDR(cidr)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	protocolNumber, err := normalizeProtocol(protocol)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	securityGroupRules := getSecurityGroupRules(t, svc, groupID, verboseOutput)
+
+	for _, ec2Rule := range securityGroupRules {
+		rule := normalizeSecurityGroupRule(securityGroupRuleFromEc2Rule(ec2Rule))
+		if rule.Egress {
+			continue
+		}
+
+		ruleCidr := rule.CidrIpv4
+		if rule.CidrIpv6 != "" {
+			ruleCidr = rule.CidrIpv6
+		}
+
+		_, parsedRuleCidr, err := net.ParseCIDR(ruleCidr)
+		if err != nil || !cidrsOverlap(forbiddenCidr, parsedRuleCidr) {
+			continue
+		}
+
+		if rule.Protocol != "-1" && protocolNumber != "-1" && rule.Protocol != protocolNumber {
+			continue
+		}
+
+		if rule.Protocol != "-1" && (port < rule.FromPort || port > rule.ToPort) {
+			continue
+		}
+
+		assert.Fail(t, "security group allows forbidden traffic", "security group %s rule %s allows %s port %d from %s", groupID, aws.StringValue(ec2Rule.SecurityGroupRuleId), protocol, port, ruleCidr)
+	}
+}
+
+// getSecurityGroupRules gets every rule of a security group, returning nil and failing the test on error
+func getSecurityGroupRules(t *testing.T, svc *ec2.EC2, groupID string, verboseOutput bool) []*ec2.SecurityGroupRule {
+	t.Helper()
+
+	securityGroupRules := []*ec2.SecurityGroupRule{}
+
+	err := svc.DescribeSecurityGroupRulesPages(
+		&ec2.DescribeSecurityGroupRulesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("group-id"),
+					Values: []*string{aws.String(groupID)},
+				},
+			},
+		},
+		func(page *ec2.DescribeSecurityGroupRulesOutput, lastPage bool) bool {
+			securityGroupRules = append(securityGroupRules, page.SecurityGroupRules...)
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return nil
+	}
+
+	if verboseOutput {
+		fmt.Println(securityGroupRules)
+	}
+
+	return securityGroupRules
+}
+
+// resolveSecurityGroupName looks up the ID of a security group by name within a VPC, returning the name unchanged when it cannot be resolved
+func resolveSecurityGroupName(t *testing.T, svc *ec2.EC2, vpcID string, groupName string) string {
+	t.Helper()
+
+	describeSecurityGroupsResult, err := svc.DescribeSecurityGroups(
+		&ec2.DescribeSecurityGroupsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("group-name"),
+					Values: []*string{aws.String(groupName)},
+				},
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []*string{aws.String(vpcID)},
+				},
+			},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return groupName
+	}
+
+	if len(describeSecurityGroupsResult.SecurityGroups) != 1 {
+		assert.Fail(t, "security group not found", "expected exactly one security group named %s in VPC %s, found %d", groupName, vpcID, len(describeSecurityGroupsResult.SecurityGroups))
+
+		return groupName
+	}
+
+	return aws.StringValue(describeSecurityGroupsResult.SecurityGroups[0].GroupId)
+}
+
+// securityGroupRuleFromEc2Rule flattens an EC2 security group rule into a SecurityGroupRule
+func securityGroupRuleFromEc2Rule(rule *ec2.SecurityGroupRule) SecurityGroupRule {
+	securityGroupRule := SecurityGroupRule{
+		Egress:       aws.BoolValue(rule.IsEgress),
+		Protocol:     aws.StringValue(rule.IpProtocol),
+		FromPort:     aws.Int64Value(rule.FromPort),
+		ToPort:       aws.Int64Value(rule.ToPort),
+		CidrIpv4:     aws.StringValue(rule.CidrIpv4),
+		CidrIpv6:     aws.StringValue(rule.CidrIpv6),
+		PrefixListID: aws.StringValue(rule.PrefixListId),
+		Description:  aws.StringValue(rule.Description),
+	}
+
+	if rule.ReferencedGroupInfo != nil {
+		securityGroupRule.ReferencedGroup = aws.StringValue(rule.ReferencedGroupInfo.GroupId)
+	}
+
+	return securityGroupRule
+}
+
+// normalizeSecurityGroupRule converts the protocol to its number and clears the ports of rules allowing all traffic
+func normalizeSecurityGroupRule(rule SecurityGroupRule) SecurityGroupRule {
+	if protocolNumber, err := normalizeProtocol(rule.Protocol); err == nil {
+		rule.Protocol = protocolNumber
+	}
+
+	if rule.Protocol == "-1" {
+		rule.FromPort = -1
+		rule.ToPort = -1
+	}
+
+	return rule
+}
+
+// containsSecurityGroupRule checks whether a rule is present in a list of rules
+func containsSecurityGroupRule(rules []SecurityGroupRule, rule SecurityGroupRule) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NetworkACLEntry struct describing an entry expected in a network ACL.
+// Protocol is tcp, udp, icmp, -1 or an IP protocol number and CidrBlock may be an IPv4 or IPv6 CIDR.
+// Ports are ignored for protocols without ports
+type NetworkACLEntry struct {
+	RuleNumber int64
+	Egress     bool
+	Protocol   string
+	FromPort   int64
+	ToPort     int64
+	CidrBlock  string
+	RuleAction string
+}
+
+// ValidateNetworkACLEntries validates that a network ACL has exactly the expected entries and is associated with exactly the expected subnets.
+// The default deny entries, numbered 32767 for IPv4 and 32768 for IPv6, are always present and are not compared
+func ValidateNetworkACLEntries(t *testing.T, svc *ec2.EC2, networkACLID string, expectedEntries []NetworkACLEntry, subnetIDs []string, verboseOutput bool) {
+	t.Helper()
+
+	describeNetworkAclsResult, err := svc.DescribeNetworkAcls(
+		&ec2.DescribeNetworkAclsInput{
+			NetworkAclIds: []*string{aws.String(networkACLID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeNetworkAclsResult.String())
+	}
+
+	if !assert.Len(t, describeNetworkAclsResult.NetworkAcls, 1, "network ACL %s not found", networkACLID) {
+		return
+	}
+
+	networkACL := describeNetworkAclsResult.NetworkAcls[0]
+
+	associatedSubnetIDs := []string{}
+	for _, association := range networkACL.Associations {
+		associatedSubnetIDs = append(associatedSubnetIDs, aws.StringValue(association.SubnetId))
+	}
+
+	assert.ElementsMatch(t, subnetIDs, associatedSubnetIDs, "subnets associated with network ACL %s", networkACLID)
+
+	actualEntries := []NetworkACLEntry{}
+
+	for _, entry := range networkACL.Entries {
+		if ruleNumber := aws.Int64Value(entry.RuleNumber); ruleNumber == 32767 || ruleNumber == 32768 {
+			continue
+		}
+
+		actualEntries = append(actualEntries, normalizeNetworkACLEntry(networkACLEntryFromEc2Entry(entry)))
+	}
+
+	normalizedExpectedEntries := []NetworkACLEntry{}
+	for _, entry := range expectedEntries {
+		normalizedExpectedEntries = append(normalizedExpectedEntries, normalizeNetworkACLEntry(entry))
+	}
+
+	missingEntries := []NetworkACLEntry{}
+
+	for _, entry := range normalizedExpectedEntries {
+		if !containsNetworkACLEntry(actualEntries, entry) {
+			missingEntries = append(missingEntries, entry)
+		}
+	}
+
+	unexpectedEntries := []NetworkACLEntry{}
+
+	for _, entry := range actualEntries {
+		if !containsNetworkACLEntry(normalizedExpectedEntries, entry) {
+			unexpectedEntries = append(unexpectedEntries, entry)
+		}
+	}
+
+	assert.Empty(t, missingEntries, "network ACL %s is missing entries", networkACLID)
+	assert.Empty(t, unexpectedEntries, "network ACL %s has unexpected entries", networkACLID)
+}
+
+// networkACLEntryFromEc2Entry flattens an EC2 network ACL entry into a NetworkACLEntry
+func networkACLEntryFromEc2Entry(entry *ec2.NetworkAclEntry) NetworkACLEntry {
+	networkACLEntry := NetworkACLEntry{
+		RuleNumber: aws.Int64Value(entry.RuleNumber),
+		Egress:     aws.BoolValue(entry.Egress),
+		Protocol:   aws.StringValue(entry.Protocol),
+		CidrBlock:  aws.StringValue(entry.CidrBlock),
+		RuleAction: aws.StringValue(entry.RuleAction),
+	}
+
+	if entry.Ipv6CidrBlock != nil {
+		networkACLEntry.CidrBlock = aws.StringValue(entry.Ipv6CidrBlock)
+	}
+
+	if entry.PortRange != nil {
+		networkACLEntry.FromPort = aws.Int64Value(entry.PortRange.From)
+		networkACLEntry.ToPort = aws.Int64Value(entry.PortRange.To)
+	}
+
+	return networkACLEntry
+}
+
+// normalizeNetworkACLEntry converts the protocol to its number and clears the ports of protocols without ports
+func normalizeNetworkACLEntry(entry NetworkACLEntry) NetworkACLEntry {
+	if protocolNumber, err := normalizeProtocol(entry.Protocol); err == nil {
+		entry.Protocol = protocolNumber
+	}
+
+	if entry.Protocol != "6" && entry.Protocol != "17" {
+		entry.FromPort = 0
+		entry.ToPort = 0
+	}
+
+	entry.RuleAction = strings.ToLower(entry.RuleAction)
+
+	return entry
+}
+
+// containsNetworkACLEntry checks whether an entry is present in a list of entries
+func containsNetworkACLEntry(entries []NetworkACLEntry, entry NetworkACLEntry) bool {
+	for _, e := range entries {
+		if e == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TransitGateway struct describing the options expected on a transit gateway.
+// Option values are enable or disable
+type TransitGateway struct {
+	TransitGatewayID             string
+	AmazonSideAsn                int64
+	DefaultRouteTableAssociation string
+	DefaultRouteTablePropagation string
+	DNSSupport                   string
+}
+
+// ValidateTransitGateway validates a transit gateway by ID is available and has the expected options
+func ValidateTransitGateway(t *testing.T, svc *ec2.EC2, transitGateway TransitGateway, verboseOutput bool) {
+	t.Helper()
+
+	describeTransitGatewaysResult, err := svc.DescribeTransitGateways(
+		&ec2.DescribeTransitGatewaysInput{
+			TransitGatewayIds: []*string{aws.String(transitGateway.TransitGatewayID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeTransitGatewaysResult.String())
+	}
+
+	if !assert.Len(t, describeTransitGatewaysResult.TransitGateways, 1, "transit gateway %s not found", transitGateway.TransitGatewayID) {
+		return
+	}
+
+	result := describeTransitGatewaysResult.TransitGateways[0]
+
+	assert.Equal(t, ec2.TransitGatewayStateAvailable, aws.StringValue(result.State))
+	assert.Equal(t, transitGateway.AmazonSideAsn, aws.Int64Value(result.Options.AmazonSideAsn), "AmazonSideAsn")
+	assert.Equal(t, transitGateway.DefaultRouteTableAssociation, aws.StringValue(result.Options.DefaultRouteTableAssociation), "DefaultRouteTableAssociation")
+	assert.Equal(t, transitGateway.DefaultRouteTablePropagation, aws.StringValue(result.Options.DefaultRouteTablePropagation), "DefaultRouteTablePropagation")
+	assert.Equal(t, transitGateway.DNSSupport, aws.StringValue(result.Options.DnsSupport), "DnsSupport")
+}
+
+// TransitGatewayVpcAttachment struct describing a VPC attachment expected on a transit gateway.
+// ApplianceModeSupport is enable or disable
+type TransitGatewayVpcAttachment struct {
+	TransitGatewayAttachmentID string
+	TransitGatewayID           string
+	VpcID                      string
+	SubnetIDs                  []string
+	ApplianceModeSupport       string
+}
+
+// ValidateTransitGatewayVpcAttachment validates a transit gateway VPC attachment by ID is available with the expected subnets and appliance mode
+func ValidateTransitGatewayVpcAttachment(t *testing.T, svc *ec2.EC2, attachment TransitGatewayVpcAttachment, verboseOutput bool) {
+	t.Helper()
+
+	describeAttachmentsResult, err := svc.DescribeTransitGatewayVpcAttachments(
+		&ec2.DescribeTransitGatewayVpcAttachmentsInput{
+			TransitGatewayAttachmentIds: []*string{aws.String(attachment.TransitGatewayAttachmentID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeAttachmentsResult.String())
+	}
+
+	if !assert.Len(t, describeAttachmentsResult.TransitGatewayVpcAttachments, 1, "transit gateway attachment %s not found", attachment.TransitGatewayAttachmentID) {
+		return
+	}
+
+	result := describeAttachmentsResult.TransitGatewayVpcAttachments[0]
+
+	assert.Equal(t, ec2.TransitGatewayAttachmentStateAvailable, aws.StringValue(result.State))
+	assert.Equal(t, attachment.TransitGatewayID, aws.StringValue(result.TransitGatewayId))
+	assert.Equal(t, attachment.VpcID, aws.StringValue(result.VpcId))
+	assert.ElementsMatch(t, attachment.SubnetIDs, aws.StringValueSlice(result.SubnetIds), "subnets of transit gateway attachment %s", attachment.TransitGatewayAttachmentID)
+	assert.Equal(t, attachment.ApplianceModeSupport, aws.StringValue(result.Options.ApplianceModeSupport), "ApplianceModeSupport")
+}
+
+// ValidateTransitGatewayRouteTable validates the exact set of attachment IDs associated with and propagating to a transit gateway route table
+func ValidateTransitGatewayRouteTable(t *testing.T, svc *ec2.EC2, routeTableID string, associatedAttachmentIDs []string, propagatingAttachmentIDs []string, verboseOutput bool) {
+	t.Helper()
+
+	associations := []string{}
+
+	err := svc.GetTransitGatewayRouteTableAssociationsPages(
+		&ec2.GetTransitGatewayRouteTableAssociationsInput{
+			TransitGatewayRouteTableId: aws.String(routeTableID),
+		},
+		func(page *ec2.GetTransitGatewayRouteTableAssociationsOutput, lastPage bool) bool {
+			if verboseOutput {
+				fmt.Println(page.String())
+			}
+
+			for _, association := range page.Associations {
+				if aws.StringValue(association.State) == ec2.TransitGatewayAssociationStateAssociated {
+					associations = append(associations, aws.StringValue(association.TransitGatewayAttachmentId))
+				}
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	propagations := []string{}
+
+	err = svc.GetTransitGatewayRouteTablePropagationsPages(
+		&ec2.GetTransitGatewayRouteTablePropagationsInput{
+			TransitGatewayRouteTableId: aws.String(routeTableID),
+		},
+		func(page *ec2.GetTransitGatewayRouteTablePropagationsOutput, lastPage bool) bool {
+			if verboseOutput {
+				fmt.Println(page.String())
+			}
+
+			for _, propagation := range page.TransitGatewayRouteTablePropagations {
+				if aws.StringValue(propagation.State) == ec2.TransitGatewayPropagationStateEnabled {
+					propagations = append(propagations, aws.StringValue(propagation.TransitGatewayAttachmentId))
+				}
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	assert.ElementsMatch(t, associatedAttachmentIDs, associations, "associations of transit gateway route table %s", routeTableID)
+	assert.ElementsMatch(t, propagatingAttachmentIDs, propagations, "propagations of transit gateway route table %s", routeTableID)
+}
+
+// TransitGatewayRoute struct describing a route expected in a transit gateway route table.
+// Destination is a CIDR or prefix list ID, Type is static or propagated and State is active or blackhole.
+// AttachmentID is empty for blackhole routes
+type TransitGatewayRoute struct {
+	Destination  string
+	AttachmentID string
+	Type         string
+	State        string
+}
+
+// ValidateTransitGatewayRoutes validates that a transit gateway route table contains exactly the expected static and propagated routes
+func ValidateTransitGatewayRoutes(t *testing.T, svc *ec2.EC2, routeTableID string, expectedRoutes []TransitGatewayRoute, verboseOutput bool) {
+	t.Helper()
+
+	searchRoutesResult, err := svc.SearchTransitGatewayRoutes(
+		&ec2.SearchTransitGatewayRoutesInput{
+			TransitGatewayRouteTableId: aws.String(routeTableID),
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("type"),
+					Values: aws.StringSlice([]string{ec2.TransitGatewayRouteTypeStatic, ec2.TransitGatewayRouteTypePropagated}),
+				},
+			},
+			MaxResults: aws.Int64(1000),
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(searchRoutesResult.String())
+	}
+
+	assert.False(t, aws.BoolValue(searchRoutesResult.AdditionalRoutesAvailable), "transit gateway route table %s has more routes than can be compared", routeTableID)
+
+	actualRoutes := []TransitGatewayRoute{}
+
+	for _, route := range searchRoutesResult.Routes {
+		destination := aws.StringValue(route.DestinationCidrBlock)
+		if route.PrefixListId != nil {
+			destination = aws.StringValue(route.PrefixListId)
+		}
+
+		actualRoute := TransitGatewayRoute{
+			Destination: destination,
+			Type:        aws.StringValue(route.Type),
+			State:       aws.StringValue(route.State),
+		}
+
+		if len(route.TransitGatewayAttachments) == 0 {
+			actualRoutes = append(actualRoutes, actualRoute)
+		}
+
+		// routes with equal cost paths list several attachments, compare each one
+		for _, attachment := range route.TransitGatewayAttachments {
+			actualRoute.AttachmentID = aws.StringValue(attachment.TransitGatewayAttachmentId)
+			actualRoutes = append(actualRoutes, actualRoute)
+		}
+	}
+
+	assert.ElementsMatch(t, expectedRoutes, actualRoutes, "routes of transit gateway route table %s", routeTableID)
+}
+
+// VpcEndpoint struct describing a VPC endpoint expected in a VPC.
+// SubnetIDs and SecurityGroupIDs apply to interface endpoints and RouteTableIDs to gateway endpoints.
+// DNSNames and PrivateDNSNames must each appear in the endpoint DNS entries. PolicyJSON is not validated when empty
+type VpcEndpoint struct {
+	ServiceName       string
+	VpcID             string
+	VpcEndpointType   string
+	State             string
+	PrivateDNSEnabled bool
+	SecurityGroupIDs  []string
+	SubnetIDs         []string
+	RouteTableIDs     []string
+	DNSNames          []string
+	PrivateDNSNames   []string
+	PolicyJSON        string
+}
+
+// ValidateVpcEndpoint validates a VPC endpoint found by service name and VPC ID, including its placement, DNS entries and policy
+func ValidateVpcEndpoint(t *testing.T, svc *ec2.EC2, vpcEndpoint VpcEndpoint, verboseOutput bool) {
+	t.Helper()
+
+	describeVpcEndpointsResult, err := svc.DescribeVpcEndpoints(
+		&ec2.DescribeVpcEndpointsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("service-name"),
+					Values: []*string{aws.String(vpcEndpoint.ServiceName)},
+				},
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []*string{aws.String(vpcEndpoint.VpcID)},
+				},
+			},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeVpcEndpointsResult.String())
+	}
+
+	if !assert.Len(t, describeVpcEndpointsResult.VpcEndpoints, 1, "VPC endpoint for %s not found in VPC %s", vpcEndpoint.ServiceName, vpcEndpoint.VpcID) {
+		return
+	}
+
+	result := describeVpcEndpointsResult.VpcEndpoints[0]
+	vpcEndpointID := aws.StringValue(result.VpcEndpointId)
+
+	assert.Equal(t, vpcEndpoint.VpcEndpointType, aws.StringValue(result.VpcEndpointType), "type of VPC endpoint %s", vpcEndpointID)
+	assert.Equal(t, vpcEndpoint.State, aws.StringValue(result.State), "state of VPC endpoint %s", vpcEndpointID)
+	assert.Equal(t, vpcEndpoint.PrivateDNSEnabled, aws.BoolValue(result.PrivateDnsEnabled), "private DNS of VPC endpoint %s", vpcEndpointID)
+
+	groupIDs := []string{}
+	for _, group := range result.Groups {
+		groupIDs = append(groupIDs, aws.StringValue(group.GroupId))
+	}
+
+	assert.ElementsMatch(t, vpcEndpoint.SecurityGroupIDs, groupIDs, "security groups of VPC endpoint %s", vpcEndpointID)
+	assert.ElementsMatch(t, vpcEndpoint.SubnetIDs, aws.StringValueSlice(result.SubnetIds), "subnets of VPC endpoint %s", vpcEndpointID)
+	assert.ElementsMatch(t, vpcEndpoint.RouteTableIDs, aws.StringValueSlice(result.RouteTableIds), "route tables of VPC endpoint %s", vpcEndpointID)
+
+	dnsNames := []string{}
+	for _, dnsEntry := range result.DnsEntries {
+		dnsNames = append(dnsNames, aws.StringValue(dnsEntry.DnsName))
+	}
+
+	assert.Subset(t, dnsNames, vpcEndpoint.DNSNames, "DNS entries of VPC endpoint %s", vpcEndpointID)
+	assert.Subset(t, dnsNames, vpcEndpoint.PrivateDNSNames, "private DNS names of VPC endpoint %s", vpcEndpointID)
+
+	if vpcEndpoint.PolicyJSON != "" {
+		ValidatePolicyDocumentsEquivalent(t, vpcEndpoint.PolicyJSON, aws.StringValue(result.PolicyDocument), verboseOutput)
+	}
+}
+
+// ValidateVpcEndpointServices validates that a VPC has endpoints for exactly the given service names
+func ValidateVpcEndpointServices(t *testing.T, svc *ec2.EC2, vpcID string, serviceNames []string, verboseOutput bool) {
+	t.Helper()
+
+	endpointServiceNames := []string{}
+
+	err := svc.DescribeVpcEndpointsPages(
+		&ec2.DescribeVpcEndpointsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []*string{aws.String(vpcID)},
+				},
+			},
+		},
+		func(page *ec2.DescribeVpcEndpointsOutput, lastPage bool) bool {
+			if verboseOutput {
+				fmt.Println(page.String())
+			}
+
+			for _, vpcEndpoint := range page.VpcEndpoints {
+				state := strings.ToLower(aws.StringValue(vpcEndpoint.State))
+				if state != "deleting" && state != "deleted" {
+					endpointServiceNames = append(endpointServiceNames, aws.StringValue(vpcEndpoint.ServiceName))
+				}
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	assert.ElementsMatch(t, serviceNames, endpointServiceNames, "VPC endpoint services of VPC %s", vpcID)
+}
+
+// logFormatFieldPattern matches a ${field} placeholder in a flow log format
+var logFormatFieldPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// FlowLog struct describing a flow log expected on a VPC, subnet, network interface or transit gateway.
+// Flow logs delivering to CloudWatch Logs are matched by LogGroupName, other flow logs by the LogDestination ARN.
+// LogGroupRetentionDays is validated through CloudWatch Logs when it is not 0.
+// FileFormat, HiveCompatiblePartitions and PerHourPartition apply to S3 destinations.
+// LogFormatFields lists the fields of a custom log format without the ${} wrapper and is not validated when nil
+type FlowLog struct {
+	ResourceID                string
+	LogDestinationType        string
+	LogDestination            string
+	LogGroupName              string
+	LogGroupRetentionDays     int64
+	TrafficType               string
+	MaxAggregationInterval    int64
+	FileFormat                string
+	HiveCompatiblePartitions  bool
+	PerHourPartition          bool
+	LogFormatFields           []string
+	LogFormatOrderInsensitive bool
+}
+
+// ValidateFlowLogs validates every expected flow log, allowing several flow logs per resource.
+// logsSvc is only used for CloudWatch Logs destinations and may be nil otherwise
+func ValidateFlowLogs(t *testing.T, svc *ec2.EC2, logsSvc *cloudwatchlogs.CloudWatchLogs, expectedFlowLogs []FlowLog, verboseOutput bool) {
+	t.Helper()
+
+	// a resource-id filter without values is rejected, and there is nothing to validate
+	if len(expectedFlowLogs) == 0 {
+		return
+	}
+
+	resourceIDs := []*string{}
+	for _, expected := range expectedFlowLogs {
+		resourceIDs = append(resourceIDs, aws.String(expected.ResourceID))
+	}
+
+	flowLogs := []*ec2.FlowLog{}
+
+	err := svc.DescribeFlowLogsPages(
+		&ec2.DescribeFlowLogsInput{
+			Filter: []*ec2.Filter{
+				{
+					Name:   aws.String("resource-id"),
+					Values: resourceIDs,
+				},
+			},
+		},
+		func(page *ec2.DescribeFlowLogsOutput, lastPage bool) bool {
+			flowLogs = append(flowLogs, page.FlowLogs...)
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(flowLogs)
+	}
+
+	for _, expected := range expectedFlowLogs {
+		var flowLog *ec2.FlowLog
+
+		for _, candidate := range flowLogs {
+			if aws.StringValue(candidate.ResourceId) != expected.ResourceID || aws.StringValue(candidate.LogDestinationType) != expected.LogDestinationType {
+				continue
+			}
+
+			if (expected.LogDestinationType == ec2.LogDestinationTypeCloudWatchLogs && aws.StringValue(candidate.LogGroupName) == expected.LogGroupName) ||
+				(expected.LogDestinationType != ec2.LogDestinationTypeCloudWatchLogs && aws.StringValue(candidate.LogDestination) == expected.LogDestination) {
+				flowLog = candidate
+
+				break
+			}
+		}
+
+		if flowLog == nil {
+			assert.Fail(t, "missing flow log", "resource %s has no %s flow log to %s%s", expected.ResourceID, expected.LogDestinationType, expected.LogGroupName, expected.LogDestination)
+
+			continue
+		}
+
+		flowLogID := aws.StringValue(flowLog.FlowLogId)
+
+		assert.Equal(t, "ACTIVE", aws.StringValue(flowLog.FlowLogStatus), "status of flow log %s", flowLogID)
+		assert.Equal(t, "SUCCESS", aws.StringValue(flowLog.DeliverLogsStatus), "delivery status of flow log %s", flowLogID)
+		assert.Equal(t, expected.TrafficType, aws.StringValue(flowLog.TrafficType), "traffic type of flow log %s", flowLogID)
+
+		if expected.MaxAggregationInterval != 0 {
+			assert.Equal(t, expected.MaxAggregationInterval, aws.Int64Value(flowLog.MaxAggregationInterval), "max aggregation interval of flow log %s", flowLogID)
+		}
+
+		if expected.LogDestinationType == ec2.LogDestinationTypeS3 && assert.NotNil(t, flowLog.DestinationOptions, "destination options of flow log %s", flowLogID) {
+			assert.Equal(t, expected.FileFormat, aws.StringValue(flowLog.DestinationOptions.FileFormat), "file format of flow log %s", flowLogID)
+			assert.Equal(t, expected.HiveCompatiblePartitions, aws.BoolValue(flowLog.DestinationOptions.HiveCompatiblePartitions), "Hive compatible partitions of flow log %s", flowLogID)
+			assert.Equal(t, expected.PerHourPartition, aws.BoolValue(flowLog.DestinationOptions.PerHourPartition), "per hour partition of flow log %s", flowLogID)
+		}
+
+		if expected.LogFormatFields != nil {
+			fields := []string{}
+			for _, match := range logFormatFieldPattern.FindAllStringSubmatch(aws.StringValue(flowLog.LogFormat), -1) {
+				fields = append(fields, match[1])
+			}
+
+			if expected.LogFormatOrderInsensitive {
+				assert.ElementsMatch(t, expected.LogFormatFields, fields, "log format of flow log %s", flowLogID)
+			} else {
+				assert.Equal(t, expected.LogFormatFields, fields, "log format of flow log %s", flowLogID)
+			}
+		}
+
+		if expected.LogDestinationType == ec2.LogDestinationTypeCloudWatchLogs && logsSvc != nil {
+			validateFlowLogGroup(t, logsSvc, expected.LogGroupName, expected.LogGroupRetentionDays, verboseOutput)
+		}
+	}
+}
+
+// validateFlowLogGroup validates that a flow log group exists and, when retentionDays is not 0, that it has the expected retention
+func validateFlowLogGroup(t *testing.T, svc *cloudwatchlogs.CloudWatchLogs, logGroupName string, retentionDays int64, verboseOutput bool) {
+	t.Helper()
+
+	var logGroup *cloudwatchlogs.LogGroup
+
+	err := svc.DescribeLogGroupsPages(
+		&cloudwatchlogs.DescribeLogGroupsInput{
+			LogGroupNamePrefix: aws.String(logGroupName),
+		},
+		func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+			if verboseOutput {
+				fmt.Println(page.String())
+			}
+
+			for _, pageLogGroup := range page.LogGroups {
+				if aws.StringValue(pageLogGroup.LogGroupName) == logGroupName {
+					logGroup = pageLogGroup
+
+					return false
+				}
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if logGroup == nil {
+		assert.Fail(t, "missing log group", "flow log group %s does not exist", logGroupName)
+
+		return
+	}
+
+	if retentionDays != 0 {
+		assert.Equal(t, retentionDays, aws.Int64Value(logGroup.RetentionInDays), "retention of log group %s", logGroupName)
+	}
+}
+
+// ValidateVpcInternetGateway validates that exactly one internet gateway is attached to a VPC and that it is the expected gateway
+func ValidateVpcInternetGateway(t *testing.T, svc *ec2.EC2, vpcID string, internetGatewayID string, verboseOutput bool) {
+	t.Helper()
+
+	describeInternetGatewaysResult, err := svc.DescribeInternetGateways(
+		&ec2.DescribeInternetGatewaysInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("attachment.vpc-id"),
+					Values: []*string{aws.String(vpcID)},
+				},
+			},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeInternetGatewaysResult.String())
+	}
+
+	if !assert.Len(t, describeInternetGatewaysResult.InternetGateways, 1, "internet gateways attached to VPC %s", vpcID) {
+		return
+	}
+
+	internetGateway := describeInternetGatewaysResult.InternetGateways[0]
+
+	assert.Equal(t, internetGatewayID, aws.StringValue(internetGateway.InternetGatewayId))
+
+	for _, attachment := range internetGateway.Attachments {
+		if aws.StringValue(attachment.VpcId) == vpcID {
+			// internet gateways report an attached VPC as available
+			assert.Equal(t, "available", aws.StringValue(attachment.State), "attachment state of internet gateway %s", internetGatewayID)
+		}
+	}
+}
+
+// ValidateVpcEgressOnlyInternetGateway validates that an egress-only internet gateway is attached to a VPC
+func ValidateVpcEgressOnlyInternetGateway(t *testing.T, svc *ec2.EC2, vpcID string, egressOnlyInternetGatewayID string, verboseOutput bool) {
+	t.Helper()
+
+	describeEgressOnlyInternetGatewaysResult, err := svc.DescribeEgressOnlyInternetGateways(
+		&ec2.DescribeEgressOnlyInternetGatewaysInput{
+			EgressOnlyInternetGatewayIds: []*string{aws.String(egressOnlyInternetGatewayID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeEgressOnlyInternetGatewaysResult.String())
+	}
+
+	if !assert.Len(t, describeEgressOnlyInternetGatewaysResult.EgressOnlyInternetGateways, 1, "egress-only internet gateway %s not found", egressOnlyInternetGatewayID) {
+		return
+	}
+
+	attachments := describeEgressOnlyInternetGatewaysResult.EgressOnlyInternetGateways[0].Attachments
+
+	if assert.Len(t, attachments, 1, "attachments of egress-only internet gateway %s", egressOnlyInternetGatewayID) {
+		assert.Equal(t, vpcID, aws.StringValue(attachments[0].VpcId))
+		assert.Equal(t, ec2.AttachmentStatusAttached, aws.StringValue(attachments[0].State))
+	}
+}
+
+// VpcPeeringConnection struct describing a VPC peering connection expected between two VPCs.
+// AccepterRegion is not validated when empty
+type VpcPeeringConnection struct {
+	VpcPeeringConnectionID      string
+	Status                      string
+	RequesterVpcID              string
+	RequesterOwnerID            string
+	RequesterAllowDNSResolution bool
+	AccepterVpcID               string
+	AccepterOwnerID             string
+	AccepterRegion              string
+	AccepterAllowDNSResolution  bool
+}
+
+// ValidateVpcPeeringConnection validates the status, both sides and DNS resolution options of a VPC peering connection
+func ValidateVpcPeeringConnection(t *testing.T, svc *ec2.EC2, peeringConnection VpcPeeringConnection, verboseOutput bool) {
+	t.Helper()
+
+	describeVpcPeeringConnectionsResult, err := svc.DescribeVpcPeeringConnections(
+		&ec2.DescribeVpcPeeringConnectionsInput{
+			VpcPeeringConnectionIds: []*string{aws.String(peeringConnection.VpcPeeringConnectionID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeVpcPeeringConnectionsResult.String())
+	}
+
+	if !assert.Len(t, describeVpcPeeringConnectionsResult.VpcPeeringConnections, 1, "VPC peering connection %s not found", peeringConnection.VpcPeeringConnectionID) {
+		return
+	}
+
+	result := describeVpcPeeringConnectionsResult.VpcPeeringConnections[0]
+
+	assert.Equal(t, peeringConnection.Status, aws.StringValue(result.Status.Code), "status")
+
+	assert.Equal(t, peeringConnection.RequesterVpcID, aws.StringValue(result.RequesterVpcInfo.VpcId), "requester VPC")
+	assert.Equal(t, peeringConnection.RequesterOwnerID, aws.StringValue(result.RequesterVpcInfo.OwnerId), "requester owner")
+	assert.Equal(t, peeringConnection.RequesterAllowDNSResolution, result.RequesterVpcInfo.PeeringOptions != nil && aws.BoolValue(result.RequesterVpcInfo.PeeringOptions.AllowDnsResolutionFromRemoteVpc), "requester DNS resolution")
+
+	assert.Equal(t, peeringConnection.AccepterVpcID, aws.StringValue(result.AccepterVpcInfo.VpcId), "accepter VPC")
+	assert.Equal(t, peeringConnection.AccepterOwnerID, aws.StringValue(result.AccepterVpcInfo.OwnerId), "accepter owner")
+	assert.Equal(t, peeringConnection.AccepterAllowDNSResolution, result.AccepterVpcInfo.PeeringOptions != nil && aws.BoolValue(result.AccepterVpcInfo.PeeringOptions.AllowDnsResolutionFromRemoteVpc), "accepter DNS resolution")
+
+	if peeringConnection.AccepterRegion != "" {
+		assert.Equal(t, peeringConnection.AccepterRegion, aws.StringValue(result.AccepterVpcInfo.Region), "accepter region")
+	}
+}
+
+// ElasticIP struct describing an Elastic IP allocation and what it is associated with.
+// Leave InstanceID and NetworkInterfaceID empty to validate that the address is not associated
+type ElasticIP struct {
+	AllocationID       string
+	PublicIP           string
+	InstanceID         string
+	NetworkInterfaceID string
+}
+
+// ValidateElasticIP validates the public IP and association of an Elastic IP allocation
+func ValidateElasticIP(t *testing.T, svc *ec2.EC2, elasticIP ElasticIP, verboseOutput bool) {
+	t.Helper()
+
+	describeAddressesResult, err := svc.DescribeAddresses(
+		&ec2.DescribeAddressesInput{
+			AllocationIds: []*string{aws.String(elasticIP.AllocationID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeAddressesResult.String())
+	}
+
+	if !assert.Len(t, describeAddressesResult.Addresses, 1, "Elastic IP %s not found", elasticIP.AllocationID) {
+		return
+	}
+
+	address := describeAddressesResult.Addresses[0]
+
+	assert.Equal(t, elasticIP.PublicIP, aws.StringValue(address.PublicIp), "public IP of %s", elasticIP.AllocationID)
+	assert.Equal(t, elasticIP.InstanceID, aws.StringValue(address.InstanceId), "instance associated with %s", elasticIP.AllocationID)
+
+	if elasticIP.NetworkInterfaceID != "" || elasticIP.InstanceID == "" {
+		assert.Equal(t, elasticIP.NetworkInterfaceID, aws.StringValue(address.NetworkInterfaceId), "network interface associated with %s", elasticIP.AllocationID)
+	}
+}
+
+// ValidateVpcDhcpOptions validates the domain name and DNS servers of the DHCP options set associated with a VPC.
+// An empty domainName is not validated
+func ValidateVpcDhcpOptions(t *testing.T, svc *ec2.EC2, vpcID string, domainName string, domainNameServers []string, verboseOutput bool) {
+	t.Helper()
+
+	describeVpcResult, err := svc.DescribeVpcs(
+		&ec2.DescribeVpcsInput{
+			VpcIds: []*string{aws.String(vpcID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if !assert.Len(t, describeVpcResult.Vpcs, 1, "VPC %s not found", vpcID) {
+		return
+	}
+
+	dhcpOptionsID := aws.StringValue(describeVpcResult.Vpcs[0].DhcpOptionsId)
+
+	// a VPC without a DHCP options set reports "default", which cannot be described and resolves through the Amazon provided DNS
+	if dhcpOptionsID == "default" {
+		if verboseOutput {
+			fmt.Printf("VPC %s has no DHCP options set\n", vpcID)
+		}
+
+		assert.Empty(t, domainName, "VPC %s has no DHCP options set, so no domain name", vpcID)
+
+		if len(domainNameServers) > 0 {
+			assert.Equal(t, []string{"AmazonProvidedDNS"}, domainNameServers, "VPC %s has no DHCP options set, so it uses AmazonProvidedDNS", vpcID)
+		}
+
+		return
+	}
+
+	describeDhcpOptionsResult, err := svc.DescribeDhcpOptions(
+		&ec2.DescribeDhcpOptionsInput{
+			DhcpOptionsIds: []*string{aws.String(dhcpOptionsID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeDhcpOptionsResult.String())
+	}
+
+	if !assert.Len(t, describeDhcpOptionsResult.DhcpOptions, 1, "DHCP options of VPC %s not found", vpcID) {
+		return
+	}
+
+	configurations := map[string][]string{}
+
+	for _, configuration := range describeDhcpOptionsResult.DhcpOptions[0].DhcpConfigurations {
+		for _, value := range configuration.Values {
+			configurations[aws.StringValue(configuration.Key)] = append(configurations[aws.StringValue(configuration.Key)], aws.StringValue(value.Value))
+		}
+	}
+
+	if domainName != "" {
+		assert.Equal(t, []string{domainName}, configurations["domain-name"], "domain name of VPC %s", vpcID)
+	}
+
+	assert.ElementsMatch(t, domainNameServers, configurations["domain-name-servers"], "DNS servers of VPC %s", vpcID)
+}
+
+// NatGateway struct describing a NAT gateway expected in a VPC.
+// Gateways are matched to the actual ones by SubnetID. ConnectivityType defaults to public when empty.
+// Empty AllocationID, PublicIP and PrivateIP values are not validated
+type NatGateway struct {
+	SubnetID         string
+	ConnectivityType string
+	AllocationID     string
+	PublicIP         string
+	PrivateIP        string
+	State            string
+}
+
+// ValidateVpcNatGateways validates the NAT gateways in a VPC against the expected list.
+// Gateways that are deleted are ignored, any other gateway that is not expected fails the test
+func ValidateVpcNatGateways(t *testing.T, svc *ec2.EC2, vpcID string, expectedNatGateways []NatGateway, verboseOutput bool) {
+	t.Helper()
+
+	natGateways, err := getVpcNatGateways(svc, vpcID, ec2.NatGatewayStatePending, ec2.NatGatewayStateAvailable, ec2.NatGatewayStateFailed, ec2.NatGatewayStateDeleting)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	actualBySubnet := map[string]*ec2.NatGateway{}
+
+	for _, natGateway := range natGateways {
+		if verboseOutput {
+			fmt.Println(natGateway.String())
+		}
+
+		subnetID := aws.StringValue(natGateway.SubnetId)
+		if _, duplicate := actualBySubnet[subnetID]; duplicate {
+			assert.Fail(t, "multiple NAT gateways found", "subnet %s of VPC %s has more than one NAT gateway", subnetID, vpcID)
+		}
+
+		actualBySubnet[subnetID] = natGateway
+	}
+
+	for _, expected := range expectedNatGateways {
+		actual, ok := actualBySubnet[expected.SubnetID]
+		if !ok {
+			assert.Fail(t, "NAT gateway not found", "no NAT gateway in subnet %s of VPC %s", expected.SubnetID, vpcID)
+
+			continue
+		}
+
+		delete(actualBySubnet, expected.SubnetID)
+
+		natGatewayID := aws.StringValue(actual.NatGatewayId)
+
+		connectivityType := expected.ConnectivityType
+		if connectivityType == "" {
+			connectivityType = ec2.ConnectivityTypePublic
+		}
+
+		assert.Equal(t, connectivityType, aws.StringValue(actual.ConnectivityType), "connectivity type of NAT gateway %s", natGatewayID)
+		assert.Equal(t, expected.State, aws.StringValue(actual.State), "state of NAT gateway %s", natGatewayID)
+
+		if expected.AllocationID == "" && expected.PublicIP == "" && expected.PrivateIP == "" {
+			continue
+		}
+
+		if !assert.NotEmpty(t, actual.NatGatewayAddresses, "addresses of NAT gateway %s", natGatewayID) {
+			continue
+		}
+
+		address := actual.NatGatewayAddresses[0]
+		for _, natGatewayAddress := range actual.NatGatewayAddresses {
+			if aws.BoolValue(natGatewayAddress.IsPrimary) {
+				address = natGatewayAddress
+			}
+		}
+
+		if expected.AllocationID != "" {
+			assert.Equal(t, expected.AllocationID, aws.StringValue(address.AllocationId), "allocation of NAT gateway %s", natGatewayID)
+		}
+
+		if expected.PublicIP != "" {
+			assert.Equal(t, expected.PublicIP, aws.StringValue(address.PublicIp), "public IP of NAT gateway %s", natGatewayID)
+		}
+
+		if expected.PrivateIP != "" {
+			assert.Equal(t, expected.PrivateIP, aws.StringValue(address.PrivateIp), "private IP of NAT gateway %s", natGatewayID)
+		}
+	}
+
+	for subnetID, natGateway := range actualBySubnet {
+		assert.Fail(t, "unexpected NAT gateway", "NAT gateway %s in subnet %s of VPC %s is not expected", aws.StringValue(natGateway.NatGatewayId), subnetID, vpcID)
+	}
+}
+
+// WaitForVpcNatGatewaysAvailable blocks until the NAT gateway in each expected subnet of a VPC is available, failing the test
+// if a subnet has no NAT gateway, any NAT gateway in the VPC has failed or the timeout expires. NAT gateways typically
+// take a few minutes to become available after creation
+func WaitForVpcNatGatewaysAvailable(t *testing.T, svc *ec2.EC2, vpcID string, expectedSubnetIDs []string, timeout time.Duration, verboseOutput bool) {
+	t.Helper()
+
+	natGateways, err := getVpcNatGateways(svc, vpcID, ec2.NatGatewayStatePending, ec2.NatGatewayStateAvailable, ec2.NatGatewayStateFailed)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	natGatewayIDsBySubnet := map[string][]*string{}
+	ready := true
+
+	for _, natGateway := range natGateways {
+		if verboseOutput {
+			fmt.Println(natGateway.String())
+		}
+
+		if aws.StringValue(natGateway.State) == ec2.NatGatewayStateFailed {
+			assert.Fail(t, "NAT gateway failed", "NAT gateway %s in subnet %s of VPC %s failed: %s", aws.StringValue(natGateway.NatGatewayId), aws.StringValue(natGateway.SubnetId), vpcID, aws.StringValue(natGateway.FailureMessage))
+
+			ready = false
+
+			continue
+		}
+
+		subnetID := aws.StringValue(natGateway.SubnetId)
+		natGatewayIDsBySubnet[subnetID] = append(natGatewayIDsBySubnet[subnetID], natGateway.NatGatewayId)
+	}
+
+	if !assert.NotEmpty(t, expectedSubnetIDs, "no subnets to wait for NAT gateways in VPC %s", vpcID) {
+		return
+	}
+
+	natGatewayIDs := []*string{}
+
+	for _, subnetID := range expectedSubnetIDs {
+		if !assert.NotEmpty(t, natGatewayIDsBySubnet[subnetID], "no pending or available NAT gateway in subnet %s of VPC %s", subnetID, vpcID) {
+			ready = false
+
+			continue
+		}
+
+		natGatewayIDs = append(natGatewayIDs, natGatewayIDsBySubnet[subnetID]...)
+	}
+
+	if !ready {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// the context bounds the wait, so the waiter's own attempt limit is lifted
+	err = svc.WaitUntilNatGatewayAvailableWithContext(
+		ctx,
+		&ec2.DescribeNatGatewaysInput{NatGatewayIds: natGatewayIDs},
+		request.WithWaiterMaxAttempts(0),
+		request.WithWaiterDelay(request.ConstantWaiterDelay(15*time.Second)),
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Printf("NAT gateways %v in VPC %s are available\n", aws.StringValueSlice(natGatewayIDs), vpcID)
+	}
+}
+
+// getVpcNatGateways returns the NAT gateways of a VPC in any of the given states
+func getVpcNatGateways(svc *ec2.EC2, vpcID string, states ...string) ([]*ec2.NatGateway, error) {
+	natGateways := []*ec2.NatGateway{}
+
+	err := svc.DescribeNatGatewaysPages(
+		&ec2.DescribeNatGatewaysInput{
+			Filter: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []*string{aws.String(vpcID)},
+				},
+				{
+					Name:   aws.String("state"),
+					Values: aws.StringSlice(states),
+				},
+			},
+		},
+		func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+			natGateways = append(natGateways, page.NatGateways...)
+
+			return true
+		},
+	)
+
+	return natGateways, err
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mustParseCIDR parses a CIDR known to be valid in a test table
+func mustParseCIDR(t *testing.T, cidr string) *net.IPNet {
+	t.Helper()
+
+	_, parsed, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed
+}
+
+func TestCidrWithinAny(t *testing.T) {
+	tests := []struct {
+		cidr    string
+		parents []string
+		within  bool
+	}{
+		{"10.0.1.0/24", []string{"10.0.0.0/16"}, true},
+		{"10.0.0.0/16", []string{"10.0.0.0/16"}, true},
+		{"10.0.0.0/15", []string{"10.0.0.0/16"}, false},
+		{"10.1.0.0/24", []string{"10.0.0.0/16"}, false},
+		{"10.1.0.0/24", []string{"10.0.0.0/16", "10.1.0.0/16"}, true},
+		{"2600:1f18::/64", []string{"2600:1f18::/56"}, true},
+		{"10.0.1.0/24", []string{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cidr, func(t *testing.T) {
+			parents := []*net.IPNet{}
+			for _, parent := range test.parents {
+				parents = append(parents, mustParseCIDR(t, parent))
+			}
+
+			assert.Equal(t, test.within, cidrWithinAny(mustParseCIDR(t, test.cidr), parents))
+		})
+	}
+}
+
+func TestCidrsOverlap(t *testing.T) {
+	tests := []struct {
+		first   string
+		second  string
+		overlap bool
+	}{
+		{"10.0.0.0/24", "10.0.0.128/25", true},
+		{"10.0.0.128/25", "10.0.0.0/24", true},
+		{"10.0.0.0/25", "10.0.0.128/25", false},
+		{"10.0.0.0/16", "10.0.0.0/16", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.first+" "+test.second, func(t *testing.T) {
+			assert.Equal(t, test.overlap, cidrsOverlap(mustParseCIDR(t, test.first), mustParseCIDR(t, test.second)))
+		})
+	}
+}
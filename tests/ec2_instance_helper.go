@@ -0,0 +1,373 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+// Instance struct describing the hardened configuration expected on EC2 instances.
+// Instances are looked up by InstanceID when it is set, otherwise every running instance matching all of Tags is validated,
+// so one of them must be set.
+// IMDSv2 is always required and a public IP is only allowed when AllowPublicIP is set.
+// UserDataSHA256 is the hex encoded SHA-256 of the decoded user data. Empty strings, nil slices and zero hop limits are not validated
+type Instance struct {
+	InstanceID              string
+	Tags                    map[string]string
+	ImageID                 string
+	ImageOwnerID            string
+	InstanceProfileArn      string
+	SecurityGroupIDs        []string
+	EbsKmsKeyArn            string
+	HTTPPutResponseHopLimit int64
+	UserDataSHA256          string
+	DetailedMonitoring      bool
+	AllowPublicIP           bool
+}
+
+// LaunchTemplate struct describing the hardened configuration expected on a launch template version.
+// Version defaults to $Default. IMDSv2 is always required and public IP association is only allowed when AllowPublicIP is set.
+// Launch templates keep the KMS key exactly as it was supplied, so EbsKmsKeyArn must match the stored key ID, alias or ARN.
+// Empty strings, nil slices and zero hop limits are not validated
+type LaunchTemplate struct {
+	LaunchTemplateID        string
+	Version                 string
+	ImageID                 string
+	InstanceProfileArn      string
+	SecurityGroupIDs        []string
+	EbsKmsKeyArn            string
+	HTTPPutResponseHopLimit int64
+	UserDataSHA256          string
+	DetailedMonitoring      bool
+	AllowPublicIP           bool
+}
+
+// ValidateInstance validates the metadata options, EBS encryption, instance profile, network exposure, AMI, user data
+// and monitoring of the EC2 instances matching the Instance struct
+func ValidateInstance(t *testing.T, svc *ec2.EC2, instance Instance, verboseOutput bool) {
+	t.Helper()
+
+	// without a selector every running instance in the account would be validated
+	if instance.InstanceID == "" && len(instance.Tags) == 0 {
+		assert.Fail(t, "no instance selector", "set InstanceID or Tags to select the instances to validate")
+
+		return
+	}
+
+	describeInstancesInput := &ec2.DescribeInstancesInput{}
+
+	if instance.InstanceID != "" {
+		describeInstancesInput.InstanceIds = []*string{aws.String(instance.InstanceID)}
+	} else {
+		describeInstancesInput.Filters = []*ec2.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []*string{aws.String(ec2.InstanceStateNameRunning)},
+			},
+		}
+
+		for key, value := range instance.Tags {
+			describeInstancesInput.Filters = append(describeInstancesInput.Filters, &ec2.Filter{
+				Name:   aws.String("tag:" + key),
+				Values: []*string{aws.String(value)},
+			})
+		}
+	}
+
+	instances := []*ec2.Instance{}
+
+	err := svc.DescribeInstancesPages(describeInstancesInput, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			instances = append(instances, reservation.Instances...)
+		}
+
+		return true
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(instances)
+	}
+
+	if !assert.NotEmpty(t, instances, "no EC2 instance found matching %s %v", instance.InstanceID, instance.Tags) {
+		return
+	}
+
+	for _, result := range instances {
+		instanceID := aws.StringValue(result.InstanceId)
+
+		if assert.NotNil(t, result.MetadataOptions, "metadata options of instance %s", instanceID) {
+			assert.Equal(t, ec2.HttpTokensStateRequired, aws.StringValue(result.MetadataOptions.HttpTokens), "IMDSv2 of instance %s", instanceID)
+
+			if instance.HTTPPutResponseHopLimit != 0 {
+				assert.Equal(t, instance.HTTPPutResponseHopLimit, aws.Int64Value(result.MetadataOptions.HttpPutResponseHopLimit), "metadata hop limit of instance %s", instanceID)
+			}
+		}
+
+		if !instance.AllowPublicIP {
+			assert.Empty(t, aws.StringValue(result.PublicIpAddress), "public IP of instance %s", instanceID)
+		}
+
+		if instance.ImageID != "" {
+			assert.Equal(t, instance.ImageID, aws.StringValue(result.ImageId), "AMI of instance %s", instanceID)
+		}
+
+		if instance.ImageOwnerID != "" {
+			assert.Equal(t, instance.ImageOwnerID, getImageOwnerID(t, svc, aws.StringValue(result.ImageId), verboseOutput), "AMI owner of instance %s", instanceID)
+		}
+
+		if instance.InstanceProfileArn != "" {
+			instanceProfileArn := ""
+			if result.IamInstanceProfile != nil {
+				instanceProfileArn = aws.StringValue(result.IamInstanceProfile.Arn)
+			}
+
+			assert.Equal(t, instance.InstanceProfileArn, instanceProfileArn, "instance profile of instance %s", instanceID)
+		}
+
+		if instance.SecurityGroupIDs != nil {
+			groupIDs := []string{}
+			for _, group := range result.SecurityGroups {
+				groupIDs = append(groupIDs, aws.StringValue(group.GroupId))
+			}
+
+			assert.ElementsMatch(t, instance.SecurityGroupIDs, groupIDs, "security groups of instance %s", instanceID)
+		}
+
+		monitoringState := ""
+		if result.Monitoring != nil {
+			monitoringState = aws.StringValue(result.Monitoring.State)
+		}
+
+		assert.Equal(t, instance.DetailedMonitoring, monitoringState == ec2.MonitoringStateEnabled, "detailed monitoring of instance %s", instanceID)
+
+		validateInstanceVolumes(t, svc, result, instance.EbsKmsKeyArn, verboseOutput)
+
+		if instance.UserDataSHA256 != "" {
+			validateInstanceUserData(t, svc, instanceID, instance.UserDataSHA256, verboseOutput)
+		}
+	}
+}
+
+// ValidateLaunchTemplate validates the metadata options, EBS encryption, instance profile, network exposure, AMI, user data
+// and monitoring of a launch template version
+func ValidateLaunchTemplate(t *testing.T, svc *ec2.EC2, launchTemplate LaunchTemplate, verboseOutput bool) {
+	t.Helper()
+
+	version := launchTemplate.Version
+	if version == "" {
+		version = "$Default"
+	}
+
+	describeLaunchTemplateVersionsResult, err := svc.DescribeLaunchTemplateVersions(
+		&ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: aws.String(launchTemplate.LaunchTemplateID),
+			Versions:         []*string{aws.String(version)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeLaunchTemplateVersionsResult.String())
+	}
+
+	if !assert.Len(t, describeLaunchTemplateVersionsResult.LaunchTemplateVersions, 1, "launch template %s version %s not found", launchTemplate.LaunchTemplateID, version) {
+		return
+	}
+
+	data := describeLaunchTemplateVersionsResult.LaunchTemplateVersions[0].LaunchTemplateData
+
+	if assert.NotNil(t, data.MetadataOptions, "metadata options of launch template %s", launchTemplate.LaunchTemplateID) {
+		assert.Equal(t, ec2.LaunchTemplateHttpTokensStateRequired, aws.StringValue(data.MetadataOptions.HttpTokens), "IMDSv2 of launch template %s", launchTemplate.LaunchTemplateID)
+
+		if launchTemplate.HTTPPutResponseHopLimit != 0 {
+			assert.Equal(t, launchTemplate.HTTPPutResponseHopLimit, aws.Int64Value(data.MetadataOptions.HttpPutResponseHopLimit), "metadata hop limit of launch template %s", launchTemplate.LaunchTemplateID)
+		}
+	}
+
+	securityGroupIDs := aws.StringValueSlice(data.SecurityGroupIds)
+
+	for _, networkInterface := range data.NetworkInterfaces {
+		if !launchTemplate.AllowPublicIP {
+			assert.False(t, aws.BoolValue(networkInterface.AssociatePublicIpAddress), "public IP association of launch template %s", launchTemplate.LaunchTemplateID)
+		}
+
+		securityGroupIDs = append(securityGroupIDs, aws.StringValueSlice(networkInterface.Groups)...)
+	}
+
+	if launchTemplate.SecurityGroupIDs != nil {
+		assert.ElementsMatch(t, launchTemplate.SecurityGroupIDs, securityGroupIDs, "security groups of launch template %s", launchTemplate.LaunchTemplateID)
+	}
+
+	if launchTemplate.ImageID != "" {
+		assert.Equal(t, launchTemplate.ImageID, aws.StringValue(data.ImageId), "AMI of launch template %s", launchTemplate.LaunchTemplateID)
+	}
+
+	if launchTemplate.InstanceProfileArn != "" {
+		instanceProfileArn := ""
+		if data.IamInstanceProfile != nil {
+			instanceProfileArn = aws.StringValue(data.IamInstanceProfile.Arn)
+		}
+
+		assert.Equal(t, launchTemplate.InstanceProfileArn, instanceProfileArn, "instance profile of launch template %s", launchTemplate.LaunchTemplateID)
+	}
+
+	for _, blockDeviceMapping := range data.BlockDeviceMappings {
+		if blockDeviceMapping.Ebs == nil {
+			continue
+		}
+
+		deviceName := aws.StringValue(blockDeviceMapping.DeviceName)
+
+		assert.True(t, aws.BoolValue(blockDeviceMapping.Ebs.Encrypted), "encryption of launch template %s device %s", launchTemplate.LaunchTemplateID, deviceName)
+
+		if launchTemplate.EbsKmsKeyArn != "" {
+			assert.Equal(t, launchTemplate.EbsKmsKeyArn, aws.StringValue(blockDeviceMapping.Ebs.KmsKeyId), "KMS key as stored in launch template %s device %s", launchTemplate.LaunchTemplateID, deviceName)
+		}
+	}
+
+	monitoringEnabled := data.Monitoring != nil && aws.BoolValue(data.Monitoring.Enabled)
+	assert.Equal(t, launchTemplate.DetailedMonitoring, monitoringEnabled, "detailed monitoring of launch template %s", launchTemplate.LaunchTemplateID)
+
+	if launchTemplate.UserDataSHA256 != "" {
+		assert.Equal(t, launchTemplate.UserDataSHA256, userDataSHA256(t, aws.StringValue(data.UserData)), "user data of launch template %s", launchTemplate.LaunchTemplateID)
+	}
+}
+
+// validateInstanceVolumes validates that every EBS volume attached to an instance is encrypted, with the given KMS key when it is set
+func validateInstanceVolumes(t *testing.T, svc *ec2.EC2, instance *ec2.Instance, kmsKeyArn string, verboseOutput bool) {
+	t.Helper()
+
+	volumeIDs := []*string{}
+
+	for _, blockDeviceMapping := range instance.BlockDeviceMappings {
+		if blockDeviceMapping.Ebs != nil {
+			volumeIDs = append(volumeIDs, blockDeviceMapping.Ebs.VolumeId)
+		}
+	}
+
+	if len(volumeIDs) == 0 {
+		return
+	}
+
+	describeVolumesResult, err := svc.DescribeVolumes(
+		&ec2.DescribeVolumesInput{
+			VolumeIds: volumeIDs,
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeVolumesResult.String())
+	}
+
+	for _, volume := range describeVolumesResult.Volumes {
+		volumeID := aws.StringValue(volume.VolumeId)
+
+		assert.True(t, aws.BoolValue(volume.Encrypted), "encryption of volume %s on instance %s", volumeID, aws.StringValue(instance.InstanceId))
+
+		if kmsKeyArn != "" {
+			assert.Equal(t, kmsKeyArn, aws.StringValue(volume.KmsKeyId), "KMS key of volume %s on instance %s", volumeID, aws.StringValue(instance.InstanceId))
+		}
+	}
+}
+
+// validateInstanceUserData validates the SHA-256 of the user data of an instance
+func validateInstanceUserData(t *testing.T, svc *ec2.EC2, instanceID string, expectedSHA256 string, verboseOutput bool) {
+	t.Helper()
+
+	describeInstanceAttributeResult, err := svc.DescribeInstanceAttribute(
+		&ec2.DescribeInstanceAttributeInput{
+			InstanceId: aws.String(instanceID),
+			Attribute:  aws.String(ec2.InstanceAttributeNameUserData),
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(describeInstanceAttributeResult.String())
+	}
+
+	userData := ""
+	if describeInstanceAttributeResult.UserData != nil {
+		userData = aws.StringValue(describeInstanceAttributeResult.UserData.Value)
+	}
+
+	assert.Equal(t, expectedSHA256, userDataSHA256(t, userData), "user data of instance %s", instanceID)
+}
+
+// userDataSHA256 decodes base64 encoded user data and returns the hex encoded SHA-256 of its content
+func userDataSHA256(t *testing.T, encodedUserData string) string {
+	t.Helper()
+
+	userData, err := base64.StdEncoding.DecodeString(encodedUserData)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return ""
+	}
+
+	sum := sha256.Sum256(userData)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// getImageOwnerID gets the account ID owning an AMI
+func getImageOwnerID(t *testing.T, svc *ec2.EC2, imageID string, verboseOutput bool) string {
+	t.Helper()
+
+	describeImagesResult, err := svc.DescribeImages(
+		&ec2.DescribeImagesInput{
+			ImageIds: []*string{aws.String(imageID)},
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return ""
+	}
+
+	if verboseOutput {
+		fmt.Println(describeImagesResult.String())
+	}
+
+	if len(describeImagesResult.Images) == 0 {
+		return ""
+	}
+
+	return aws.StringValue(describeImagesResult.Images[0].OwnerId)
+}
@@ -0,0 +1,532 @@
+package tests
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+// Next hop types returned by EvaluatePath
+const (
+	NextHopNone                      = "none"
+	NextHopLocal                     = "local"
+	NextHopInternetGateway           = "internet-gateway"
+	NextHopEgressOnlyInternetGateway = "egress-only-internet-gateway"
+	NextHopNatGateway                = "nat-gateway"
+	NextHopTransitGateway            = "transit-gateway"
+	NextHopVpcPeeringConnection      = "vpc-peering-connection"
+	NextHopVpcEndpoint               = "vpc-endpoint"
+	NextHopVirtualPrivateGateway     = "virtual-private-gateway"
+	NextHopNetworkInterface          = "network-interface"
+	NextHopOther                     = "other"
+)
+
+// VpcNetwork struct containing a snapshot of the VPC configuration used to evaluate reachability offline.
+// Build it with GetVpcNetwork, then evaluate as many paths as needed without further AWS calls
+type VpcNetwork struct {
+	VpcID                     string
+	Subnets                   []*ec2.Subnet
+	RouteTables               []*ec2.RouteTable
+	NetworkAcls               []*ec2.NetworkAcl
+	InternetGateways          []*ec2.InternetGateway
+	NatGateways               []*ec2.NatGateway
+	TransitGatewayAttachments []*ec2.TransitGatewayVpcAttachment
+}
+
+// Path struct describing how traffic from a subnet to a destination leaves the subnet.
+// NextHopActive is false when the target of the matched route is missing, not attached or not available.
+// NetworkACLAllowed only reflects the outbound entries of the subnet network ACL, evaluated for the network address
+// of a CIDR destination. Entries covering only part of a CIDR destination and inbound return traffic are not evaluated
+type Path struct {
+	SubnetID             string
+	Destination          string
+	RouteDestination     string
+	NextHopType          string
+	NextHopID            string
+	NextHopActive        bool
+	NetworkACLID         string
+	NetworkACLRuleNumber int64
+	NetworkACLAllowed    bool
+}
+
+// GetVpcNetwork describes the subnets, route tables, network ACLs, internet gateways, NAT gateways and
+// transit gateway attachments of a VPC and returns them as a VpcNetwork
+func GetVpcNetwork(t *testing.T, svc *ec2.EC2, vpcID string, verboseOutput bool) *VpcNetwork {
+	t.Helper()
+
+	network := &VpcNetwork{VpcID: vpcID}
+
+	vpcFilter := []*ec2.Filter{
+		{
+			Name:   aws.String("vpc-id"),
+			Values: []*string{aws.String(vpcID)},
+		},
+	}
+
+	err := svc.DescribeSubnetsPages(
+		&ec2.DescribeSubnetsInput{Filters: vpcFilter},
+		func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+			network.Subnets = append(network.Subnets, page.Subnets...)
+
+			return true
+		},
+	)
+	if err == nil {
+		err = svc.DescribeRouteTablesPages(
+			&ec2.DescribeRouteTablesInput{Filters: vpcFilter},
+			func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+				network.RouteTables = append(network.RouteTables, page.RouteTables...)
+
+				return true
+			},
+		)
+	}
+
+	if err == nil {
+		err = svc.DescribeNetworkAclsPages(
+			&ec2.DescribeNetworkAclsInput{Filters: vpcFilter},
+			func(page *ec2.DescribeNetworkAclsOutput, lastPage bool) bool {
+				network.NetworkAcls = append(network.NetworkAcls, page.NetworkAcls...)
+
+				return true
+			},
+		)
+	}
+
+	if err == nil {
+		err = svc.DescribeInternetGatewaysPages(
+			&ec2.DescribeInternetGatewaysInput{
+				Filters: []*ec2.Filter{
+					{
+						Name:   aws.String("attachment.vpc-id"),
+						Values: []*string{aws.String(vpcID)},
+					},
+				},
+			},
+			func(page *ec2.DescribeInternetGatewaysOutput, lastPage bool) bool {
+				network.InternetGateways = append(network.InternetGateways, page.InternetGateways...)
+
+				return true
+			},
+		)
+	}
+
+	if err == nil {
+		err = svc.DescribeNatGatewaysPages(
+			&ec2.DescribeNatGatewaysInput{Filter: vpcFilter},
+			func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+				network.NatGateways = append(network.NatGateways, page.NatGateways...)
+
+				return true
+			},
+		)
+	}
+
+	if err == nil {
+		err = svc.DescribeTransitGatewayVpcAttachmentsPages(
+			&ec2.DescribeTransitGatewayVpcAttachmentsInput{Filters: vpcFilter},
+			func(page *ec2.DescribeTransitGatewayVpcAttachmentsOutput, lastPage bool) bool {
+				network.TransitGatewayAttachments = append(network.TransitGatewayAttachments, page.TransitGatewayVpcAttachments...)
+
+				return true
+			},
+		)
+	}
+
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return network
+	}
+
+	if verboseOutput {
+		fmt.Println(network.Subnets)
+		fmt.Println(network.RouteTables)
+		fmt.Println(network.NetworkAcls)
+		fmt.Println(network.InternetGateways)
+		fmt.Println(network.NatGateways)
+		fmt.Println(network.TransitGatewayAttachments)
+	}
+
+	return network
+}
+
+// EvaluatePath computes the route and outbound network ACL verdict for traffic leaving a subnet.
+// destination is an IP address or CIDR, protocol is tcp, udp, icmp, all or an IP protocol number
+// and port is ignored for protocols without ports.
+// Routes to prefix lists are not resolved and never match
+func (network *VpcNetwork) EvaluatePath(subnetID string, destination string, protocol string, port int64) (Path, error) {
+	path := Path{
+		SubnetID:    subnetID,
+		Destination: destination,
+		NextHopType: NextHopNone,
+	}
+
+	destinationIP, destinationOnes, err := parseDestination(destination)
+	if err != nil {
+		return path, err
+	}
+
+	subnet := network.subnet(subnetID)
+	if subnet == nil {
+		return path, fmt.Errorf("subnet %s not found in VPC %s", subnetID, network.VpcID)
+	}
+
+	routeTable := subnetRouteTable(network.RouteTables, subnetID)
+	if routeTable == nil {
+		return path, fmt.Errorf("no route table found for subnet %s", subnetID)
+	}
+
+	bestOnes := -1
+
+	for _, ec2Route := range routeTable.Routes {
+		route := routeFromEc2Route(ec2Route)
+
+		_, routeCidr, err := net.ParseCIDR(route.Destination)
+		if err != nil || !routeCidr.Contains(destinationIP) {
+			continue
+		}
+
+		routeOnes, _ := routeCidr.Mask.Size()
+		if routeOnes > destinationOnes || routeOnes <= bestOnes {
+			continue
+		}
+
+		bestOnes = routeOnes
+		path.RouteDestination = route.Destination
+		path.NextHopID = route.Target
+		path.NextHopType = nextHopType(route.Target)
+		path.NextHopActive = aws.StringValue(ec2Route.State) == ec2.RouteStateActive && network.nextHopActive(path.NextHopType, path.NextHopID, aws.StringValue(subnet.AvailabilityZone))
+	}
+
+	networkACL := network.subnetNetworkACL(subnetID)
+	if networkACL == nil {
+		return path, fmt.Errorf("no network ACL found for subnet %s", subnetID)
+	}
+
+	path.NetworkACLID = aws.StringValue(networkACL.NetworkAclId)
+
+	protocolNumber, err := normalizeProtocol(protocol)
+	if err != nil {
+		return path, err
+	}
+
+	entry := evaluateNetworkACL(networkACL.Entries, true, destinationIP, protocolNumber, port)
+	if entry != nil {
+		path.NetworkACLRuleNumber = aws.Int64Value(entry.RuleNumber)
+		path.NetworkACLAllowed = aws.StringValue(entry.RuleAction) == ec2.RuleActionAllow
+	}
+
+	return path, nil
+}
+
+// ValidateSubnetRoutesTo validates that traffic from a subnet to a destination is routed to an active next hop
+// of the expected type and is allowed by the subnet network ACL. As described on Path, only the outbound network ACL
+// entries are checked, so inbound rules for return traffic need their own ValidateNetworkACLEntries assertion
+func ValidateSubnetRoutesTo(t *testing.T, network *VpcNetwork, subnetID string, destination string, protocol string, port int64, nextHopType string, verboseOutput bool) {
+	t.Helper()
+
+	path, err := network.EvaluatePath(subnetID, destination, protocol, port)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Printf("%+v\n", path)
+	}
+
+	assert.Equal(t, nextHopType, path.NextHopType, "next hop from subnet %s to %s", subnetID, destination)
+	assert.True(t, path.NextHopActive, "next hop %s from subnet %s to %s is not active", path.NextHopID, subnetID, destination)
+	assert.True(t, path.NetworkACLAllowed, "network ACL %s denies %s port %d from subnet %s to %s (rule %d)", path.NetworkACLID, protocol, port, subnetID, destination, path.NetworkACLRuleNumber)
+}
+
+// ValidateSubnetHasNoRouteTo validates that traffic from a subnet to a destination is not routed to a next hop of the given type.
+// Use NextHopNone as nextHopType to validate that the subnet has no route to the destination at all.
+// Only the most specific route for the destination is considered, use ValidateSubnetHasNoRouteVia to rule out a next hop type entirely
+func ValidateSubnetHasNoRouteTo(t *testing.T, network *VpcNetwork, subnetID string, destination string, nextHopType string, verboseOutput bool) {
+	t.Helper()
+
+	path, err := network.EvaluatePath(subnetID, destination, "all", 0)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Printf("%+v\n", path)
+	}
+
+	if nextHopType == NextHopNone {
+		assert.Equal(t, NextHopNone, path.NextHopType, "subnet %s routes %s to %s via %s", subnetID, destination, path.NextHopID, path.RouteDestination)
+	} else {
+		assert.NotEqual(t, nextHopType, path.NextHopType, "subnet %s routes %s to %s via %s", subnetID, destination, path.NextHopID, path.RouteDestination)
+	}
+}
+
+// ValidateSubnetHasNoRouteVia validates that no route in the route table of a subnet, for any destination, targets a next hop of the given type
+func ValidateSubnetHasNoRouteVia(t *testing.T, network *VpcNetwork, subnetID string, nextHopType string, verboseOutput bool) {
+	t.Helper()
+
+	routes, err := network.SubnetRoutesVia(subnetID, nextHopType)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Printf("%+v\n", routes)
+	}
+
+	for _, route := range routes {
+		assert.Fail(t, "unexpected route", "subnet %s routes %s to %s %s", subnetID, route.Destination, nextHopType, route.Target)
+	}
+}
+
+// SubnetRoutesVia returns every route in the route table of a subnet whose target is a next hop of the given type
+func (network *VpcNetwork) SubnetRoutesVia(subnetID string, hopType string) ([]Route, error) {
+	routeTable := subnetRouteTable(network.RouteTables, subnetID)
+	if routeTable == nil {
+		return nil, fmt.Errorf("no route table found for subnet %s", subnetID)
+	}
+
+	routes := []Route{}
+
+	for _, ec2Route := range routeTable.Routes {
+		route := routeFromEc2Route(ec2Route)
+
+		if nextHopType(route.Target) == hopType {
+			routes = append(routes, route)
+		}
+	}
+
+	return routes, nil
+}
+
+// ValidateSubnetReachesInternetViaNat validates that a subnet reaches the internet through an available public NAT gateway
+// whose own subnet routes to an internet gateway, and that the subnet network ACL allows the traffic
+func ValidateSubnetReachesInternetViaNat(t *testing.T, network *VpcNetwork, subnetID string, protocol string, port int64, verboseOutput bool) {
+	t.Helper()
+
+	ValidateSubnetRoutesTo(t, network, subnetID, "0.0.0.0/0", protocol, port, NextHopNatGateway, verboseOutput)
+
+	path, err := network.EvaluatePath(subnetID, "0.0.0.0/0", protocol, port)
+	if err != nil || path.NextHopType != NextHopNatGateway {
+		return
+	}
+
+	for _, natGateway := range network.NatGateways {
+		if aws.StringValue(natGateway.NatGatewayId) != path.NextHopID {
+			continue
+		}
+
+		assert.Equal(t, ec2.ConnectivityTypePublic, aws.StringValue(natGateway.ConnectivityType), "NAT gateway %s connectivity type", path.NextHopID)
+		ValidateSubnetRoutesTo(t, network, aws.StringValue(natGateway.SubnetId), "0.0.0.0/0", protocol, port, NextHopInternetGateway, verboseOutput)
+	}
+}
+
+// subnet returns the subnet with the given ID, or nil when it is not part of the network
+func (network *VpcNetwork) subnet(subnetID string) *ec2.Subnet {
+	for _, subnet := range network.Subnets {
+		if aws.StringValue(subnet.SubnetId) == subnetID {
+			return subnet
+		}
+	}
+
+	return nil
+}
+
+// subnetNetworkACL returns the network ACL associated with a subnet
+func (network *VpcNetwork) subnetNetworkACL(subnetID string) *ec2.NetworkAcl {
+	for _, networkACL := range network.NetworkAcls {
+		for _, association := range networkACL.Associations {
+			if aws.StringValue(association.SubnetId) == subnetID {
+				return networkACL
+			}
+		}
+	}
+
+	return nil
+}
+
+// nextHopActive checks that the target of a route exists in the network and is usable from the given availability zone
+func (network *VpcNetwork) nextHopActive(nextHopType string, nextHopID string, availabilityZone string) bool {
+	switch nextHopType {
+	case NextHopLocal:
+		return true
+	case NextHopInternetGateway:
+		for _, internetGateway := range network.InternetGateways {
+			if aws.StringValue(internetGateway.InternetGatewayId) != nextHopID {
+				continue
+			}
+
+			for _, attachment := range internetGateway.Attachments {
+				state := aws.StringValue(attachment.State)
+				if aws.StringValue(attachment.VpcId) == network.VpcID && (state == "available" || state == ec2.AttachmentStatusAttached) {
+					return true
+				}
+			}
+		}
+
+		return false
+	case NextHopNatGateway:
+		for _, natGateway := range network.NatGateways {
+			if aws.StringValue(natGateway.NatGatewayId) == nextHopID {
+				return aws.StringValue(natGateway.State) == ec2.NatGatewayStateAvailable
+			}
+		}
+
+		return false
+	case NextHopTransitGateway:
+		for _, attachment := range network.TransitGatewayAttachments {
+			if aws.StringValue(attachment.TransitGatewayId) != nextHopID || aws.StringValue(attachment.State) != ec2.TransitGatewayAttachmentStateAvailable {
+				continue
+			}
+
+			// traffic only reaches the transit gateway when the attachment has a subnet in the same availability zone
+			for _, attachmentSubnetID := range attachment.SubnetIds {
+				attachmentSubnet := network.subnet(aws.StringValue(attachmentSubnetID))
+				if attachmentSubnet != nil && aws.StringValue(attachmentSubnet.AvailabilityZone) == availabilityZone {
+					return true
+				}
+			}
+		}
+
+		return false
+	default:
+		// the remaining targets are not described by GetVpcNetwork, so an active route is trusted
+		return true
+	}
+}
+
+// nextHopType returns the next hop type of a route target based on its ID
+func nextHopType(target string) string {
+	switch {
+	case target == "local":
+		return NextHopLocal
+	case strings.HasPrefix(target, "igw-"):
+		return NextHopInternetGateway
+	case strings.HasPrefix(target, "eigw-"):
+		return NextHopEgressOnlyInternetGateway
+	case strings.HasPrefix(target, "nat-"):
+		return NextHopNatGateway
+	case strings.HasPrefix(target, "tgw-"):
+		return NextHopTransitGateway
+	case strings.HasPrefix(target, "pcx-"):
+		return NextHopVpcPeeringConnection
+	case strings.HasPrefix(target, "vpce-"):
+		return NextHopVpcEndpoint
+	case strings.HasPrefix(target, "vgw-"):
+		return NextHopVirtualPrivateGateway
+	case strings.HasPrefix(target, "eni-"):
+		return NextHopNetworkInterface
+	default:
+		return NextHopOther
+	}
+}
+
+// parseDestination parses an IP address or CIDR and returns its address and prefix length
+func parseDestination(destination string) (net.IP, int, error) {
+	if strings.Contains(destination, "/") {
+		_, cidr, err := net.ParseCIDR(destination)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		ones, _ := cidr.Mask.Size()
+
+		return cidr.IP, ones, nil
+	}
+
+	ip := net.ParseIP(destination)
+	if ip == nil {
+		return nil, 0, fmt.Errorf("invalid destination %s", destination)
+	}
+
+	if ip.To4() != nil {
+		return ip, 32, nil
+	}
+
+	return ip, 128, nil
+}
+
+// normalizeProtocol converts a protocol name to the IP protocol number used by network ACL entries
+func normalizeProtocol(protocol string) (string, error) {
+	switch strings.ToLower(protocol) {
+	case "all", "-1", "":
+		return "-1", nil
+	case "tcp":
+		return "6", nil
+	case "udp":
+		return "17", nil
+	case "icmp":
+		return "1", nil
+	case "icmpv6":
+		return "58", nil
+	}
+
+	if _, err := strconv.Atoi(protocol); err != nil {
+		return "", fmt.Errorf("invalid protocol %s", protocol)
+	}
+
+	return protocol, nil
+}
+
+// evaluateNetworkACL returns the first network ACL entry in rule number order matching the traffic, or nil when none match
+func evaluateNetworkACL(entries []*ec2.NetworkAclEntry, egress bool, ip net.IP, protocol string, port int64) *ec2.NetworkAclEntry {
+	sorted := make([]*ec2.NetworkAclEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		if aws.BoolValue(entry.Egress) == egress {
+			sorted = append(sorted, entry)
+		}
+	}
+
+	sort.Slice(sorted, func(i, x int) bool {
+		return aws.Int64Value(sorted[i].RuleNumber) < aws.Int64Value(sorted[x].RuleNumber)
+	})
+
+	for _, entry := range sorted {
+		cidrBlock := aws.StringValue(entry.CidrBlock)
+		if entry.Ipv6CidrBlock != nil {
+			cidrBlock = aws.StringValue(entry.Ipv6CidrBlock)
+		}
+
+		_, cidr, err := net.ParseCIDR(cidrBlock)
+		if err != nil || !cidr.Contains(ip) {
+			continue
+		}
+
+		entryProtocol := aws.StringValue(entry.Protocol)
+		if entryProtocol != "-1" && entryProtocol != protocol {
+			continue
+		}
+
+		if entryProtocol != "-1" && (protocol == "6" || protocol == "17") && entry.PortRange != nil &&
+			(port < aws.Int64Value(entry.PortRange.From) || port > aws.Int64Value(entry.PortRange.To)) {
+			continue
+		}
+
+		return entry
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+// testVpcNetwork returns a network with a private subnet routing the internet through a NAT gateway,
+// a more specific range through an internet gateway and a peered range through a peering connection
+func testVpcNetwork(networkACLEntries []*ec2.NetworkAclEntry) *VpcNetwork {
+	return &VpcNetwork{
+		VpcID: "vpc-1",
+		Subnets: []*ec2.Subnet{
+			{SubnetId: aws.String("subnet-private"), AvailabilityZone: aws.String("us-east-1a")},
+		},
+		RouteTables: []*ec2.RouteTable{
+			{
+				RouteTableId: aws.String("rtb-private"),
+				Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-private")}},
+				Routes: []*ec2.Route{
+					{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), State: aws.String(ec2.RouteStateActive)},
+					{DestinationCidrBlock: aws.String("0.0.0.0/0"), NatGatewayId: aws.String("nat-1"), State: aws.String(ec2.RouteStateActive)},
+					{DestinationCidrBlock: aws.String("1.2.3.0/24"), GatewayId: aws.String("igw-1"), State: aws.String(ec2.RouteStateActive)},
+					{DestinationCidrBlock: aws.String("10.1.0.0/16"), VpcPeeringConnectionId: aws.String("pcx-1"), State: aws.String(ec2.RouteStateActive)},
+				},
+			},
+		},
+		NetworkAcls: []*ec2.NetworkAcl{
+			{
+				NetworkAclId: aws.String("acl-1"),
+				Associations: []*ec2.NetworkAclAssociation{{SubnetId: aws.String("subnet-private")}},
+				Entries:      networkACLEntries,
+			},
+		},
+		InternetGateways: []*ec2.InternetGateway{
+			{
+				InternetGatewayId: aws.String("igw-1"),
+				Attachments:       []*ec2.InternetGatewayAttachment{{VpcId: aws.String("vpc-1"), State: aws.String("available")}},
+			},
+		},
+		NatGateways: []*ec2.NatGateway{
+			{NatGatewayId: aws.String("nat-1"), State: aws.String(ec2.NatGatewayStateAvailable)},
+		},
+	}
+}
+
+// testNetworkACLEntry returns an outbound network ACL entry
+func testNetworkACLEntry(ruleNumber int64, protocol string, cidrBlock string, from int64, to int64, ruleAction string) *ec2.NetworkAclEntry {
+	entry := &ec2.NetworkAclEntry{
+		RuleNumber: aws.Int64(ruleNumber),
+		Egress:     aws.Bool(true),
+		Protocol:   aws.String(protocol),
+		CidrBlock:  aws.String(cidrBlock),
+		RuleAction: aws.String(ruleAction),
+	}
+
+	if from != 0 || to != 0 {
+		entry.PortRange = &ec2.PortRange{From: aws.Int64(from), To: aws.Int64(to)}
+	}
+
+	return entry
+}
+
+func TestEvaluatePathLongestPrefixMatch(t *testing.T) {
+	network := testVpcNetwork([]*ec2.NetworkAclEntry{
+		testNetworkACLEntry(100, "-1", "0.0.0.0/0", 0, 0, ec2.RuleActionAllow),
+	})
+
+	tests := []struct {
+		name             string
+		destination      string
+		routeDestination string
+		nextHopType      string
+		nextHopID        string
+	}{
+		{"default route", "8.8.8.8", "0.0.0.0/0", NextHopNatGateway, "nat-1"},
+		{"more specific route wins", "1.2.3.4", "1.2.3.0/24", NextHopInternetGateway, "igw-1"},
+		{"local route", "10.0.5.5", "10.0.0.0/16", NextHopLocal, "local"},
+		{"peered range", "10.1.2.3/24", "10.1.0.0/16", NextHopVpcPeeringConnection, "pcx-1"},
+		{"destination wider than the specific route", "1.2.0.0/16", "0.0.0.0/0", NextHopNatGateway, "nat-1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := network.EvaluatePath("subnet-private", test.destination, "tcp", 443)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.routeDestination, path.RouteDestination)
+			assert.Equal(t, test.nextHopType, path.NextHopType)
+			assert.Equal(t, test.nextHopID, path.NextHopID)
+			assert.True(t, path.NextHopActive)
+		})
+	}
+}
+
+func TestEvaluatePathErrors(t *testing.T) {
+	network := testVpcNetwork(nil)
+
+	_, err := network.EvaluatePath("subnet-missing", "8.8.8.8", "tcp", 443)
+	assert.Error(t, err)
+
+	_, err = network.EvaluatePath("subnet-private", "not-an-ip", "tcp", 443)
+	assert.Error(t, err)
+
+	_, err = network.EvaluatePath("subnet-private", "8.8.8.8", "sctp", 443)
+	assert.Error(t, err)
+}
+
+func TestEvaluateNetworkACLRuleOrdering(t *testing.T) {
+	tests := []struct {
+		name       string
+		entries    []*ec2.NetworkAclEntry
+		protocol   string
+		port       int64
+		ruleNumber int64
+		allowed    bool
+	}{
+		{
+			name: "lowest rule number wins regardless of entry order",
+			entries: []*ec2.NetworkAclEntry{
+				testNetworkACLEntry(200, "-1", "0.0.0.0/0", 0, 0, ec2.RuleActionAllow),
+				testNetworkACLEntry(100, "6", "8.8.8.0/24", 443, 443, ec2.RuleActionDeny),
+			},
+			protocol:   "tcp",
+			port:       443,
+			ruleNumber: 100,
+			allowed:    false,
+		},
+		{
+			name: "port outside the range falls through",
+			entries: []*ec2.NetworkAclEntry{
+				testNetworkACLEntry(100, "6", "8.8.8.0/24", 80, 80, ec2.RuleActionDeny),
+				testNetworkACLEntry(200, "6", "0.0.0.0/0", 443, 443, ec2.RuleActionAllow),
+			},
+			protocol:   "tcp",
+			port:       443,
+			ruleNumber: 200,
+			allowed:    true,
+		},
+		{
+			name: "protocol mismatch falls through",
+			entries: []*ec2.NetworkAclEntry{
+				testNetworkACLEntry(100, "17", "0.0.0.0/0", 443, 443, ec2.RuleActionAllow),
+				testNetworkACLEntry(32767, "-1", "0.0.0.0/0", 0, 0, ec2.RuleActionDeny),
+			},
+			protocol:   "tcp",
+			port:       443,
+			ruleNumber: 32767,
+			allowed:    false,
+		},
+		{
+			name:       "no matching entry is denied",
+			entries:    []*ec2.NetworkAclEntry{},
+			protocol:   "all",
+			ruleNumber: 0,
+			allowed:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := testVpcNetwork(test.entries).EvaluatePath("subnet-private", "8.8.8.8", test.protocol, test.port)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "acl-1", path.NetworkACLID)
+			assert.Equal(t, test.ruleNumber, path.NetworkACLRuleNumber)
+			assert.Equal(t, test.allowed, path.NetworkACLAllowed)
+		})
+	}
+}
+
+func TestEvaluateNetworkACLIgnoresIngress(t *testing.T) {
+	ingress := testNetworkACLEntry(100, "-1", "0.0.0.0/0", 0, 0, ec2.RuleActionAllow)
+	ingress.Egress = aws.Bool(false)
+
+	assert.Nil(t, evaluateNetworkACL([]*ec2.NetworkAclEntry{ingress}, true, net.ParseIP("8.8.8.8"), "-1", 0))
+}
+
+func TestSubnetRoutesVia(t *testing.T) {
+	network := testVpcNetwork(nil)
+
+	tests := []struct {
+		nextHopType string
+		routes      []Route
+	}{
+		{NextHopInternetGateway, []Route{{Destination: "1.2.3.0/24", Target: "igw-1"}}},
+		{NextHopNatGateway, []Route{{Destination: "0.0.0.0/0", Target: "nat-1"}}},
+		{NextHopTransitGateway, []Route{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.nextHopType, func(t *testing.T) {
+			routes, err := network.SubnetRoutesVia("subnet-private", test.nextHopType)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.routes, routes)
+		})
+	}
+}
+
+func TestNormalizeProtocol(t *testing.T) {
+	tests := map[string]string{
+		"tcp":    "6",
+		"UDP":    "17",
+		"icmp":   "1",
+		"icmpv6": "58",
+		"all":    "-1",
+		"":       "-1",
+		"50":     "50",
+	}
+
+	for protocol, expected := range tests {
+		actual, err := normalizeProtocol(protocol)
+
+		assert.NoError(t, err, protocol)
+		assert.Equal(t, expected, actual, protocol)
+	}
+
+	_, err := normalizeProtocol("sctp")
+	assert.Error(t, err)
+}
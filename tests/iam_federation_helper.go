@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+// OIDCProvider struct describing an IAM OpenID Connect provider such as the one of an EKS cluster or a CI system.
+// URL may be given with or without the https:// scheme. Thumbprints are not validated when nil
+type OIDCProvider struct {
+	Arn         string
+	URL         string
+	ClientIDs   []string
+	Thumbprints []string
+}
+
+// samlEntityDescriptor holds the parts of a SAML metadata document the SAML validators check
+type samlEntityDescriptor struct {
+	XMLName      xml.Name `xml:"EntityDescriptor"`
+	EntityID     string   `xml:"entityID,attr"`
+	ValidUntil   string   `xml:"validUntil,attr"`
+	Certificates []string `xml:"IDPSSODescriptor>KeyDescriptor>KeyInfo>X509Data>X509Certificate"`
+}
+
+// ValidateOIDCProvider validates the URL, client IDs and thumbprints of an OpenID Connect provider
+func ValidateOIDCProvider(t *testing.T, svc *iam.IAM, expectedProvider OIDCProvider, verboseOutput bool) {
+	t.Helper()
+
+	providerResult, err := svc.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: aws.String(expectedProvider.Arn),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(providerResult.String())
+	}
+
+	// IAM stores the provider URL without its scheme
+	assert.Equal(t, strings.TrimPrefix(expectedProvider.URL, "https://"), strings.TrimPrefix(aws.StringValue(providerResult.Url), "https://"), "URL of %s", expectedProvider.Arn)
+	assert.ElementsMatch(t, expectedProvider.ClientIDs, aws.StringValueSlice(providerResult.ClientIDList), "client IDs of %s", expectedProvider.Arn)
+
+	if expectedProvider.Thumbprints != nil {
+		assert.ElementsMatch(t, lowerCaseStrings(expectedProvider.Thumbprints), lowerCaseStrings(aws.StringValueSlice(providerResult.ThumbprintList)), "thumbprints of %s", expectedProvider.Arn)
+	}
+}
+
+// ValidateSAMLProviderMetadata validates the entity ID of a SAML provider and that neither the provider nor its signing
+// certificates have expired. Expiry dates within expiryWarning of now are logged as warnings without failing the test
+func ValidateSAMLProviderMetadata(t *testing.T, svc *iam.IAM, providerArn string, entityID string, expiryWarning time.Duration, verboseOutput bool) {
+	t.Helper()
+
+	providerResult, err := svc.GetSAMLProvider(&iam.GetSAMLProviderInput{
+		SAMLProviderArn: aws.String(providerArn),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(providerResult.String())
+	}
+
+	var metadata samlEntityDescriptor
+	if err := xml.Unmarshal([]byte(aws.StringValue(providerResult.SAMLMetadataDocument)), &metadata); err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	assert.Equal(t, entityID, metadata.EntityID, "entity ID of %s", providerArn)
+
+	if providerResult.ValidUntil != nil {
+		validateExpiry(t, fmt.Sprintf("SAML provider %s", providerArn), aws.TimeValue(providerResult.ValidUntil), expiryWarning)
+	}
+
+	if metadata.ValidUntil != "" {
+		validUntil, err := time.Parse(time.RFC3339, metadata.ValidUntil)
+		if assert.NoError(t, err, "validUntil of %s metadata", providerArn) {
+			validateExpiry(t, fmt.Sprintf("metadata of SAML provider %s", providerArn), validUntil, expiryWarning)
+		}
+	}
+
+	if !assert.NotEmpty(t, metadata.Certificates, "SAML provider %s metadata has no signing certificate", providerArn) {
+		return
+	}
+
+	for _, encodedCertificate := range metadata.Certificates {
+		// certificates in metadata documents are usually wrapped across lines
+		der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encodedCertificate), ""))
+		if !assert.NoError(t, err, "certificate of SAML provider %s", providerArn) {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(der)
+		if !assert.NoError(t, err, "certificate of SAML provider %s", providerArn) {
+			continue
+		}
+
+		validateExpiry(t, fmt.Sprintf("certificate %q of SAML provider %s", certificate.Subject.String(), providerArn), certificate.NotAfter, expiryWarning)
+	}
+}
+
+// validateExpiry fails the test when the expiry date has passed and logs a warning when it falls within the warning threshold
+func validateExpiry(t *testing.T, description string, expiry time.Time, expiryWarning time.Duration) {
+	t.Helper()
+
+	now := time.Now()
+
+	if !assert.True(t, expiry.After(now), "%s expired on %s", description, expiry.Format(time.RFC3339)) {
+		return
+	}
+
+	if expiry.Before(now.Add(expiryWarning)) {
+		t.Logf("WARNING: %s expires on %s", description, expiry.Format(time.RFC3339))
+	}
+}
+
+// lowerCaseStrings returns a copy of the values in lower case
+func lowerCaseStrings(values []string) []string {
+	lowered := []string{}
+
+	for _, value := range values {
+		lowered = append(lowered, strings.ToLower(value))
+	}
+
+	return lowered
+}
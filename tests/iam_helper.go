@@ -168,6 +168,8 @@ func ValidateRoleArn(t *testing.T, svc *iam.IAM, roleName string, roleArn string
 }
 
 // ValidatePolicyIsAttachedToASpecificGroup gets policy and checks it is attached to a specific group
+//
+// Deprecated: ValidatePolicyIsAttachedToASpecificGroup only checks the first page of attached groups. Use ValidatePolicyEntities instead.
 func ValidatePolicyIsAttachedToASpecificGroup(t *testing.T, svc *iam.IAM, policyArn string, groupName string, verboseOutput bool) {
 	t.Helper()
 
@@ -243,6 +245,8 @@ func ValidateGroup(t *testing.T, svc *iam.IAM, groupName string, groupArn string
 }
 
 // ValidateGroupIsAttachedToASpecificUser get the group and the user attached
+//
+// Deprecated: ValidateGroupIsAttachedToASpecificUser only supports a group with a single member, reads only the first page of members and panics on an empty group. Use ValidateGroupMembership to validate the exact members.
 func ValidateGroupIsAttachedToASpecificUser(t *testing.T, svc *iam.IAM, groupName string, groupArn string, userName string, userArn string, verboseOutput bool) {
 	t.Helper()
 
@@ -284,6 +288,8 @@ func ValidateGroupIsAttachedToASpecificUser(t *testing.T, svc *iam.IAM, groupNam
 }
 
 // ValidatePolicyIsAttachedToARole get polcy by arn and validates that at least one role is attached
+//
+// Deprecated: ValidatePolicyIsAttachedToARole passes when any role has the policy. Use ValidatePolicyEntities to validate the exact roles.
 func ValidatePolicyIsAttachedToARole(t *testing.T, svc *iam.IAM, policyArn string, verboseOutput bool) {
 	t.Helper()
 
@@ -393,8 +399,62 @@ func ValidateRoleHasManagedPolicyAttached(t *testing.T, svc *iam.IAM, policyArn
 	assert.Contains(t, policyRolesResult.String(), policyArn)
 }
 
-// ValidateAccountPasswordPolicy gets the account password policy and validates it
-func ValidateAccountPasswordPolicy(t *testing.T, svc *iam.IAM, verboseOutput bool) {
+// PasswordPolicy struct describing the account password policy a test expects.
+// Nil fields are not validated. MinimumPasswordLength and PasswordReusePrevention are minimums and
+// MaxPasswordAge is a maximum, so a stricter account policy still passes. The boolean fields must match exactly
+type PasswordPolicy struct {
+	MinimumPasswordLength      *int64
+	PasswordReusePrevention    *int64
+	MaxPasswordAge             *int64
+	RequireUppercaseCharacters *bool
+	RequireLowercaseCharacters *bool
+	RequireNumbers             *bool
+	RequireSymbols             *bool
+	AllowUsersToChangePassword *bool
+	HardExpiry                 *bool
+}
+
+// LegacyPasswordPolicy returns the password policy ValidateAccountPasswordPolicy checked before it took the expected
+// policy as an argument: 90 day expiry, length 8, reuse 3, all character classes, hard expiry and self service changes.
+// The numeric values are now minimums and maximums, so a stricter account policy passes where it used to fail
+func LegacyPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinimumPasswordLength:      aws.Int64(8),
+		PasswordReusePrevention:    aws.Int64(3),
+		MaxPasswordAge:             aws.Int64(90),
+		RequireUppercaseCharacters: aws.Bool(true),
+		RequireLowercaseCharacters: aws.Bool(true),
+		RequireNumbers:             aws.Bool(true),
+		RequireSymbols:             aws.Bool(true),
+		AllowUsersToChangePassword: aws.Bool(true),
+		HardExpiry:                 aws.Bool(true),
+	}
+}
+
+// CISv12PasswordPolicy returns the password policy required by CIS AWS Foundations Benchmark v1.2 controls 1.5 to 1.11
+func CISv12PasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinimumPasswordLength:      aws.Int64(14),
+		PasswordReusePrevention:    aws.Int64(24),
+		MaxPasswordAge:             aws.Int64(90),
+		RequireUppercaseCharacters: aws.Bool(true),
+		RequireLowercaseCharacters: aws.Bool(true),
+		RequireNumbers:             aws.Bool(true),
+		RequireSymbols:             aws.Bool(true),
+	}
+}
+
+// CISv14PasswordPolicy returns the password policy required by CIS AWS Foundations Benchmark v1.4 and later,
+// which dropped the complexity and expiry controls in favour of length and reuse prevention
+func CISv14PasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinimumPasswordLength:   aws.Int64(14),
+		PasswordReusePrevention: aws.Int64(24),
+	}
+}
+
+// ValidateAccountPasswordPolicy gets the account password policy and validates it against the expected policy
+func ValidateAccountPasswordPolicy(t *testing.T, svc *iam.IAM, expectedPolicy PasswordPolicy, verboseOutput bool) {
 	t.Helper()
 
 	accountPasswordpolicyInput := &iam.GetAccountPasswordPolicyInput{}
@@ -420,15 +480,50 @@ func ValidateAccountPasswordPolicy(t *testing.T, svc *iam.IAM, verboseOutput boo
 		return
 	}
 
-	assert.Equal(t, true, *accountPasswordPolicyResult.PasswordPolicy.AllowUsersToChangePassword)
-	assert.Equal(t, true, *accountPasswordPolicyResult.PasswordPolicy.HardExpiry)
-	assert.Equal(t, int64(90), *accountPasswordPolicyResult.PasswordPolicy.MaxPasswordAge)
-	assert.Equal(t, int64(8), *accountPasswordPolicyResult.PasswordPolicy.MinimumPasswordLength)
-	assert.Equal(t, int64(3), *accountPasswordPolicyResult.PasswordPolicy.PasswordReusePrevention)
-	assert.Equal(t, true, *accountPasswordPolicyResult.PasswordPolicy.RequireLowercaseCharacters)
-	assert.Equal(t, true, *accountPasswordPolicyResult.PasswordPolicy.RequireNumbers)
-	assert.Equal(t, true, *accountPasswordPolicyResult.PasswordPolicy.RequireSymbols)
-	assert.Equal(t, true, *accountPasswordPolicyResult.PasswordPolicy.RequireUppercaseCharacters)
+	if verboseOutput {
+		fmt.Println(accountPasswordPolicyResult.String())
+	}
+
+	policy := accountPasswordPolicyResult.PasswordPolicy
+
+	if expectedPolicy.MinimumPasswordLength != nil {
+		assert.GreaterOrEqual(t, aws.Int64Value(policy.MinimumPasswordLength), *expectedPolicy.MinimumPasswordLength, "minimum password length")
+	}
+
+	if expectedPolicy.PasswordReusePrevention != nil {
+		assert.GreaterOrEqual(t, aws.Int64Value(policy.PasswordReusePrevention), *expectedPolicy.PasswordReusePrevention, "password reuse prevention")
+	}
+
+	if expectedPolicy.MaxPasswordAge != nil {
+		// passwords that never expire are reported without a max age
+		if assert.True(t, aws.BoolValue(policy.ExpirePasswords), "passwords do not expire") {
+			assert.LessOrEqual(t, aws.Int64Value(policy.MaxPasswordAge), *expectedPolicy.MaxPasswordAge, "max password age")
+		}
+	}
+
+	if expectedPolicy.RequireUppercaseCharacters != nil {
+		assert.Equal(t, *expectedPolicy.RequireUppercaseCharacters, aws.BoolValue(policy.RequireUppercaseCharacters), "require uppercase characters")
+	}
+
+	if expectedPolicy.RequireLowercaseCharacters != nil {
+		assert.Equal(t, *expectedPolicy.RequireLowercaseCharacters, aws.BoolValue(policy.RequireLowercaseCharacters), "require lowercase characters")
+	}
+
+	if expectedPolicy.RequireNumbers != nil {
+		assert.Equal(t, *expectedPolicy.RequireNumbers, aws.BoolValue(policy.RequireNumbers), "require numbers")
+	}
+
+	if expectedPolicy.RequireSymbols != nil {
+		assert.Equal(t, *expectedPolicy.RequireSymbols, aws.BoolValue(policy.RequireSymbols), "require symbols")
+	}
+
+	if expectedPolicy.AllowUsersToChangePassword != nil {
+		assert.Equal(t, *expectedPolicy.AllowUsersToChangePassword, aws.BoolValue(policy.AllowUsersToChangePassword), "allow users to change password")
+	}
+
+	if expectedPolicy.HardExpiry != nil {
+		assert.Equal(t, *expectedPolicy.HardExpiry, aws.BoolValue(policy.HardExpiry), "hard expiry")
+	}
 }
 
 // ValidatePolicyDetails gets the polcy by arn and validates that the JSON permissions are correct
@@ -813,3 +908,253 @@ func GetNewestPolicyVersion(t *testing.T, svc *iam.IAM, policyArn string, verbos
 
 	return versionID
 }
+
+// GetPolicyDocument returns the URL decoded document of the default version of a managed policy
+func GetPolicyDocument(t *testing.T, svc *iam.IAM, policyArn string, verboseOutput bool) string {
+	t.Helper()
+
+	versionID := GetNewestPolicyVersion(t, svc, policyArn, verboseOutput)
+
+	policyDetailsResult, err := svc.GetPolicyVersion(&iam.GetPolicyVersionInput{
+		PolicyArn: aws.String(policyArn),
+		VersionId: aws.String(versionID),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return ""
+	}
+
+	decodedValue, err := url.QueryUnescape(aws.StringValue(policyDetailsResult.PolicyVersion.Document))
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return ""
+	}
+
+	if verboseOutput {
+		fmt.Println(decodedValue)
+	}
+
+	return decodedValue
+}
+
+// GetRoleInlinePolicyDocument returns the URL decoded document of an inline policy embedded in a role
+func GetRoleInlinePolicyDocument(t *testing.T, svc *iam.IAM, roleName string, policyName string, verboseOutput bool) string {
+	t.Helper()
+
+	roleResult, err := svc.GetRolePolicy(&iam.GetRolePolicyInput{
+		RoleName:   aws.String(roleName),
+		PolicyName: aws.String(policyName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return ""
+	}
+
+	decodedValue, err := url.QueryUnescape(aws.StringValue(roleResult.PolicyDocument))
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return ""
+	}
+
+	if verboseOutput {
+		fmt.Println(decodedValue)
+	}
+
+	return decodedValue
+}
+
+// PolicyEntities struct describing the exact users, groups and roles a managed policy is attached to, by name.
+// Empty slices mean the policy must not be attached to any entity of that type
+type PolicyEntities struct {
+	Users  []string
+	Groups []string
+	Roles  []string
+}
+
+// ValidatePolicyEntities validates the exact set of users, groups and roles a managed policy is attached to
+func ValidatePolicyEntities(t *testing.T, svc *iam.IAM, policyArn string, expectedEntities PolicyEntities, verboseOutput bool) {
+	t.Helper()
+
+	actualEntities := PolicyEntities{
+		Users:  []string{},
+		Groups: []string{},
+		Roles:  []string{},
+	}
+
+	err := svc.ListEntitiesForPolicyPages(
+		&iam.ListEntitiesForPolicyInput{
+			PolicyArn: aws.String(policyArn),
+		},
+		func(page *iam.ListEntitiesForPolicyOutput, lastPage bool) bool {
+			if verboseOutput {
+				fmt.Println(page.String())
+			}
+
+			for _, user := range page.PolicyUsers {
+				actualEntities.Users = append(actualEntities.Users, aws.StringValue(user.UserName))
+			}
+
+			for _, group := range page.PolicyGroups {
+				actualEntities.Groups = append(actualEntities.Groups, aws.StringValue(group.GroupName))
+			}
+
+			for _, role := range page.PolicyRoles {
+				actualEntities.Roles = append(actualEntities.Roles, aws.StringValue(role.RoleName))
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	assert.ElementsMatch(t, expectedEntities.Users, actualEntities.Users, "users attached to %s", policyArn)
+	assert.ElementsMatch(t, expectedEntities.Groups, actualEntities.Groups, "groups attached to %s", policyArn)
+	assert.ElementsMatch(t, expectedEntities.Roles, actualEntities.Roles, "roles attached to %s", policyArn)
+}
+
+// GroupMembership struct describing the exact members and policies of a group.
+// Empty slices mean the group must have no members, managed policies or inline policies respectively
+type GroupMembership struct {
+	GroupName         string
+	UserNames         []string
+	ManagedPolicyArns []string
+	InlinePolicyNames []string
+}
+
+// ValidateGroupMembership validates the exact set of users, attached managed policies and inline policies of a group
+func ValidateGroupMembership(t *testing.T, svc *iam.IAM, expectedGroup GroupMembership, verboseOutput bool) {
+	t.Helper()
+
+	groupName := aws.String(expectedGroup.GroupName)
+	userNames := []string{}
+
+	err := svc.GetGroupPages(
+		&iam.GetGroupInput{GroupName: groupName},
+		func(page *iam.GetGroupOutput, lastPage bool) bool {
+			if verboseOutput {
+				fmt.Println(page.String())
+			}
+
+			for _, user := range page.Users {
+				userNames = append(userNames, aws.StringValue(user.UserName))
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	managedPolicyArns := []string{}
+
+	err = svc.ListAttachedGroupPoliciesPages(
+		&iam.ListAttachedGroupPoliciesInput{GroupName: groupName},
+		func(page *iam.ListAttachedGroupPoliciesOutput, lastPage bool) bool {
+			for _, policy := range page.AttachedPolicies {
+				managedPolicyArns = append(managedPolicyArns, aws.StringValue(policy.PolicyArn))
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	inlinePolicyNames := []string{}
+
+	err = svc.ListGroupPoliciesPages(
+		&iam.ListGroupPoliciesInput{GroupName: groupName},
+		func(page *iam.ListGroupPoliciesOutput, lastPage bool) bool {
+			inlinePolicyNames = append(inlinePolicyNames, aws.StringValueSlice(page.PolicyNames)...)
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	assert.ElementsMatch(t, expectedGroup.UserNames, userNames, "members of group %s", expectedGroup.GroupName)
+	assert.ElementsMatch(t, expectedGroup.ManagedPolicyArns, managedPolicyArns, "managed policies attached to group %s", expectedGroup.GroupName)
+	assert.ElementsMatch(t, expectedGroup.InlinePolicyNames, inlinePolicyNames, "inline policies of group %s", expectedGroup.GroupName)
+}
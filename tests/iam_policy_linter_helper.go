@@ -0,0 +1,395 @@
+package tests
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+// Rules reported by the least-privilege policy linter
+const (
+	PolicyFindingWildcardAction              = "wildcard-action"
+	PolicyFindingWildcardResource            = "wildcard-resource"
+	PolicyFindingPassRoleWildcardResource    = "passrole-wildcard-resource"
+	PolicyFindingNotActionWithAllow          = "notaction-with-allow"
+	PolicyFindingSensitiveServiceNoCondition = "sensitive-service-without-condition"
+	// PolicyFindingPrivilegeEscalation only counts actions granted on resource * (or through NotResource), so
+	// escalation through resource scoped grants, such as iam:CreatePolicyVersion on the principal's own policy, is not reported
+	PolicyFindingPrivilegeEscalation = "privilege-escalation"
+)
+
+// sensitivePolicyServices are the services whose Allow statements are expected to carry a Condition block
+var sensitivePolicyServices = map[string]bool{
+	"iam":            true,
+	"kms":            true,
+	"organizations":  true,
+	"secretsmanager": true,
+	"sts":            true,
+}
+
+// privilegeEscalationCombinations are sets of actions that together let a principal grant itself more permissions
+var privilegeEscalationCombinations = [][]string{
+	{"iam:CreatePolicyVersion"},
+	{"iam:SetDefaultPolicyVersion"},
+	{"iam:CreateAccessKey"},
+	{"iam:CreateLoginProfile"},
+	{"iam:UpdateLoginProfile"},
+	{"iam:AttachUserPolicy"},
+	{"iam:AttachGroupPolicy"},
+	{"iam:AttachRolePolicy"},
+	{"iam:PutUserPolicy"},
+	{"iam:PutGroupPolicy"},
+	{"iam:PutRolePolicy"},
+	{"iam:AddUserToGroup"},
+	{"iam:UpdateAssumeRolePolicy", "sts:AssumeRole"},
+	{"iam:PassRole", "ec2:RunInstances"},
+	{"iam:PassRole", "lambda:CreateFunction", "lambda:InvokeFunction"},
+	{"iam:PassRole", "lambda:CreateFunction", "lambda:CreateEventSourceMapping"},
+	{"iam:PassRole", "cloudformation:CreateStack"},
+	{"iam:PassRole", "glue:CreateDevEndpoint"},
+	{"iam:PassRole", "datapipeline:CreatePipeline", "datapipeline:PutPipelineDefinition"},
+	{"lambda:UpdateFunctionCode"},
+	{"glue:UpdateDevEndpoint"},
+}
+
+// policyActionGrant holds the actions one Allow statement grants on every resource. A statement using NotAction
+// grants everything except its NotActions, which are kept non-nil to tell the two forms apart
+type policyActionGrant struct {
+	Actions    []string
+	NotActions []string
+}
+
+// grants reports whether the statement allows the action
+func (grant policyActionGrant) grants(action string) bool {
+	if grant.NotActions != nil {
+		return !policyActionsMatch(grant.NotActions, action)
+	}
+
+	return policyActionsMatch(grant.Actions, action)
+}
+
+// PolicyFinding struct describing one risky construct found by the least-privilege linter.
+// Statement is the Sid of the offending statement, or Statement[index] when it has none.
+// Privilege escalation findings span statements, so their Statement is "*"
+type PolicyFinding struct {
+	Rule      string
+	Policy    string
+	Statement string
+	Detail    string
+}
+
+// PolicyFindingSuppression struct describing findings that are accepted for the policies under test.
+// Empty Policy and Statement values match any policy or statement
+type PolicyFindingSuppression struct {
+	Rule      string
+	Policy    string
+	Statement string
+}
+
+// LintPolicyDocument returns the least-privilege findings of a single policy document
+func LintPolicyDocument(policyName string, policyJSON string) ([]PolicyFinding, error) {
+	findings, grants, err := lintPolicyStatements(policyName, policyJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(findings, privilegeEscalationFindings(policyName, grants)...), nil
+}
+
+// LintManagedPolicy returns the least-privilege findings of the default version of a managed policy
+func LintManagedPolicy(t *testing.T, svc *iam.IAM, policyArn string, verboseOutput bool) []PolicyFinding {
+	t.Helper()
+
+	findings, err := LintPolicyDocument(policyArn, GetPolicyDocument(t, svc, policyArn, verboseOutput))
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return nil
+	}
+
+	return findings
+}
+
+// LintRoleInlinePolicy returns the least-privilege findings of an inline policy embedded in a role
+func LintRoleInlinePolicy(t *testing.T, svc *iam.IAM, roleName string, policyName string, verboseOutput bool) []PolicyFinding {
+	t.Helper()
+
+	findings, err := LintPolicyDocument(roleName+"/"+policyName, GetRoleInlinePolicyDocument(t, svc, roleName, policyName, verboseOutput))
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return nil
+	}
+
+	return findings
+}
+
+// LintRolePolicies returns the least-privilege findings of every inline and attached managed policy of a role.
+// Privilege escalation is evaluated across all of the role's policies together, since the actions may be split between them
+func LintRolePolicies(t *testing.T, svc *iam.IAM, roleName string, verboseOutput bool) []PolicyFinding {
+	t.Helper()
+
+	documents := map[string]string{}
+
+	err := svc.ListRolePoliciesPages(
+		&iam.ListRolePoliciesInput{RoleName: aws.String(roleName)},
+		func(page *iam.ListRolePoliciesOutput, lastPage bool) bool {
+			for _, policyName := range page.PolicyNames {
+				documents[roleName+"/"+aws.StringValue(policyName)] = GetRoleInlinePolicyDocument(t, svc, roleName, aws.StringValue(policyName), verboseOutput)
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return nil
+	}
+
+	err = svc.ListAttachedRolePoliciesPages(
+		&iam.ListAttachedRolePoliciesInput{RoleName: aws.String(roleName)},
+		func(page *iam.ListAttachedRolePoliciesOutput, lastPage bool) bool {
+			for _, policy := range page.AttachedPolicies {
+				documents[aws.StringValue(policy.PolicyArn)] = GetPolicyDocument(t, svc, aws.StringValue(policy.PolicyArn), verboseOutput)
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return nil
+	}
+
+	findings := []PolicyFinding{}
+	roleGrants := []policyActionGrant{}
+
+	for policyName, document := range documents {
+		if document == "" {
+			// the failure was already reported while fetching the document
+			continue
+		}
+
+		policyFindings, grants, err := lintPolicyStatements(policyName, document)
+		if err != nil {
+			fmt.Println(policyName, err.Error())
+			t.Logf("Failing test.")
+			t.Fail()
+
+			continue
+		}
+
+		findings = append(findings, policyFindings...)
+		roleGrants = append(roleGrants, grants...)
+	}
+
+	return append(findings, privilegeEscalationFindings(roleName, roleGrants)...)
+}
+
+// ValidatePolicyFindings fails the test for every finding that is not covered by a suppression.
+// Suppressions that match no finding are logged so stale entries can be cleaned up
+func ValidatePolicyFindings(t *testing.T, findings []PolicyFinding, suppressions []PolicyFindingSuppression, verboseOutput bool) {
+	t.Helper()
+
+	usedSuppressions := make([]bool, len(suppressions))
+
+	for _, finding := range findings {
+		if verboseOutput {
+			fmt.Printf("%s %s %s: %s\n", finding.Rule, finding.Policy, finding.Statement, finding.Detail)
+		}
+
+		suppressed := false
+
+		for i, suppression := range suppressions {
+			if suppression.Rule == finding.Rule &&
+				(suppression.Policy == "" || suppression.Policy == finding.Policy) &&
+				(suppression.Statement == "" || suppression.Statement == finding.Statement) {
+				usedSuppressions[i] = true
+				suppressed = true
+			}
+		}
+
+		if !suppressed {
+			assert.Fail(t, "least-privilege finding", "%s in %s statement %s: %s", finding.Rule, finding.Policy, finding.Statement, finding.Detail)
+		}
+	}
+
+	for i, used := range usedSuppressions {
+		if !used {
+			t.Logf("suppression %+v matched no finding", suppressions[i])
+		}
+	}
+}
+
+// lintPolicyStatements returns the per statement findings of a policy document and the actions its Allow statements grant on every resource
+func lintPolicyStatements(policyName string, policyJSON string) ([]PolicyFinding, []policyActionGrant, error) {
+	document, err := decodePolicyDocument(policyJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statements, err := policyStatements(document)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	findings := []PolicyFinding{}
+	grants := []policyActionGrant{}
+
+	for i, statement := range statements {
+		if statement["Effect"] != "Allow" {
+			continue
+		}
+
+		statementID, _ := statement["Sid"].(string)
+		if statementID == "" {
+			statementID = fmt.Sprintf("Statement[%d]", i)
+		}
+
+		newFinding := func(rule string, detail string) PolicyFinding {
+			return PolicyFinding{Rule: rule, Policy: policyName, Statement: statementID, Detail: detail}
+		}
+
+		grant := policyActionGrant{Actions: sortedPolicyValues(statement["Action"])}
+		actions := grant.Actions
+		describedActions := fmt.Sprintf("actions %v", actions)
+
+		if notAction, ok := statement["NotAction"]; ok {
+			grant.NotActions = sortedPolicyValues(notAction)
+			describedActions = fmt.Sprintf("all actions except %v", grant.NotActions)
+
+			findings = append(findings, newFinding(PolicyFindingNotActionWithAllow, "Allow with NotAction grants "+describedActions))
+		}
+
+		resources := sortedPolicyValues(statement["Resource"])
+		_, hasCondition := statement["Condition"]
+		// NotResource allows everything except the listed resources, so it counts as a wildcard
+		_, wildcardResource := statement["NotResource"]
+
+		for _, action := range actions {
+			if action == "*" || strings.HasSuffix(action, ":*") {
+				findings = append(findings, newFinding(PolicyFindingWildcardAction, fmt.Sprintf("action %s", action)))
+			}
+		}
+
+		for _, resource := range resources {
+			if resource == "*" {
+				wildcardResource = true
+
+				findings = append(findings, newFinding(PolicyFindingWildcardResource, describedActions+" on resource *"))
+			}
+		}
+
+		if wildcardResource {
+			grants = append(grants, grant)
+
+			if grant.grants("iam:PassRole") {
+				findings = append(findings, newFinding(PolicyFindingPassRoleWildcardResource, "iam:PassRole on resource *"))
+			}
+		}
+
+		if !hasCondition {
+			for _, service := range sensitiveServicesOf(grant) {
+				findings = append(findings, newFinding(PolicyFindingSensitiveServiceNoCondition, fmt.Sprintf("%s actions allowed without a condition", service)))
+			}
+		}
+	}
+
+	return findings, grants, nil
+}
+
+// privilegeEscalationFindings returns a finding for every escalation combination whose actions are all granted
+func privilegeEscalationFindings(policyName string, grants []policyActionGrant) []PolicyFinding {
+	findings := []PolicyFinding{}
+
+	for _, combination := range privilegeEscalationCombinations {
+		covered := true
+
+		for _, action := range combination {
+			granted := false
+
+			for _, grant := range grants {
+				granted = granted || grant.grants(action)
+			}
+
+			if !granted {
+				covered = false
+
+				break
+			}
+		}
+
+		if covered {
+			findings = append(findings, PolicyFinding{
+				Rule:      PolicyFindingPrivilegeEscalation,
+				Policy:    policyName,
+				Statement: "*",
+				Detail:    fmt.Sprintf("allows %s", strings.Join(combination, " + ")),
+			})
+		}
+	}
+
+	return findings
+}
+
+// policyActionsMatch reports whether any of the policy action patterns, which may use * and ? wildcards, matches the action
+func policyActionsMatch(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		// action names contain no path separators, so path.Match behaves as a plain wildcard match
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(action)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sensitiveServicesOf returns the sensitive services the grant touches. A bare * touches all of them, and a NotAction
+// grant touches every service whose actions it does not exclude as a whole
+func sensitiveServicesOf(grant policyActionGrant) []string {
+	services := []string{}
+	seen := map[string]bool{}
+
+	if grant.NotActions != nil {
+		for sensitiveService := range sensitivePolicyServices {
+			if !policyActionsMatch(grant.NotActions, sensitiveService+":*") {
+				services = append(services, sensitiveService)
+			}
+		}
+
+		sort.Strings(services)
+
+		return services
+	}
+
+	for _, action := range grant.Actions {
+		service := strings.ToLower(strings.SplitN(action, ":", 2)[0])
+
+		for sensitiveService := range sensitivePolicyServices {
+			if (service == "*" || service == sensitiveService) && !seen[sensitiveService] {
+				seen[sensitiveService] = true
+				services = append(services, sensitiveService)
+			}
+		}
+	}
+
+	sort.Strings(services)
+
+	return services
+}
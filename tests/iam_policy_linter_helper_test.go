@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// findingsWithRule returns the details of the findings reported for a rule
+func findingsWithRule(findings []PolicyFinding, rule string) []string {
+	details := []string{}
+
+	for _, finding := range findings {
+		if finding.Rule == rule {
+			details = append(details, finding.Detail)
+		}
+	}
+
+	return details
+}
+
+func TestLintPolicyDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		rule     string
+		expected []string
+	}{
+		{
+			name:     "wildcard action",
+			policy:   `{"Statement":{"Effect":"Allow","Action":["s3:*","ec2:DescribeInstances"],"Resource":"arn:aws:s3:::b"}}`,
+			rule:     PolicyFindingWildcardAction,
+			expected: []string{"action s3:*"},
+		},
+		{
+			name:     "wildcard resource",
+			policy:   `{"Statement":{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}}`,
+			rule:     PolicyFindingWildcardResource,
+			expected: []string{"actions [s3:GetObject] on resource *"},
+		},
+		{
+			name:     "deny statements are ignored",
+			policy:   `{"Statement":{"Effect":"Deny","Action":"*","Resource":"*"}}`,
+			rule:     PolicyFindingWildcardAction,
+			expected: []string{},
+		},
+		{
+			name:     "PassRole on wildcard resource",
+			policy:   `{"Statement":{"Effect":"Allow","Action":"iam:Pass*","Resource":"*"}}`,
+			rule:     PolicyFindingPassRoleWildcardResource,
+			expected: []string{"iam:PassRole on resource *"},
+		},
+		{
+			name:     "PassRole on a specific role",
+			policy:   `{"Statement":{"Effect":"Allow","Action":"iam:PassRole","Resource":"arn:aws:iam::111122223333:role/app"}}`,
+			rule:     PolicyFindingPassRoleWildcardResource,
+			expected: []string{},
+		},
+		{
+			name:     "NotAction with Allow",
+			policy:   `{"Statement":{"Effect":"Allow","NotAction":"iam:*","Resource":"*"}}`,
+			rule:     PolicyFindingNotActionWithAllow,
+			expected: []string{"Allow with NotAction grants all actions except [iam:*]"},
+		},
+		{
+			name:     "NotAction wildcard resource detail",
+			policy:   `{"Statement":{"Effect":"Allow","NotAction":"iam:*","Resource":"*"}}`,
+			rule:     PolicyFindingWildcardResource,
+			expected: []string{"all actions except [iam:*] on resource *"},
+		},
+		{
+			name:     "sensitive service without condition",
+			policy:   `{"Statement":{"Effect":"Allow","Action":["kms:Decrypt","s3:GetObject"],"Resource":"arn:aws:kms:us-east-1:111122223333:key/1"}}`,
+			rule:     PolicyFindingSensitiveServiceNoCondition,
+			expected: []string{"kms actions allowed without a condition"},
+		},
+		{
+			name:     "sensitive service with condition",
+			policy:   `{"Statement":{"Effect":"Allow","Action":"kms:Decrypt","Resource":"*","Condition":{"StringEquals":{"kms:ViaService":"s3.us-east-1.amazonaws.com"}}}}`,
+			rule:     PolicyFindingSensitiveServiceNoCondition,
+			expected: []string{},
+		},
+		{
+			name:     "NotAction without condition grants sensitive services",
+			policy:   `{"Statement":{"Effect":"Allow","NotAction":["iam:*","kms:Decrypt"],"Resource":"*"}}`,
+			rule:     PolicyFindingSensitiveServiceNoCondition,
+			expected: []string{"kms actions allowed without a condition", "organizations actions allowed without a condition", "secretsmanager actions allowed without a condition", "sts actions allowed without a condition"},
+		},
+		{
+			name:     "NotAction with condition",
+			policy:   `{"Statement":{"Effect":"Allow","NotAction":"s3:*","Resource":"*","Condition":{"Bool":{"aws:MultiFactorAuthPresent":"true"}}}}`,
+			rule:     PolicyFindingSensitiveServiceNoCondition,
+			expected: []string{},
+		},
+		{
+			name:     "NotAction excludes escalation actions",
+			policy:   `{"Statement":{"Effect":"Allow","NotAction":["iam:*","lambda:*","glue:*"],"Resource":"*"}}`,
+			rule:     PolicyFindingPrivilegeEscalation,
+			expected: []string{},
+		},
+		{
+			name:     "NotAction grants the remaining escalation actions",
+			policy:   `{"Statement":{"Effect":"Allow","NotAction":"iam:*","Resource":"*"}}`,
+			rule:     PolicyFindingPrivilegeEscalation,
+			expected: []string{"allows lambda:UpdateFunctionCode", "allows glue:UpdateDevEndpoint"},
+		},
+		{
+			name: "escalation combination across statements",
+			policy: `{"Statement":[
+				{"Effect":"Allow","Action":"iam:PassRole","Resource":"*"},
+				{"Effect":"Allow","Action":"ec2:Run*","Resource":"*"}
+			]}`,
+			rule:     PolicyFindingPrivilegeEscalation,
+			expected: []string{"allows iam:PassRole + ec2:RunInstances"},
+		},
+		{
+			name: "escalation ignores resource scoped grants",
+			policy: `{"Statement":[
+				{"Effect":"Allow","Action":"iam:PassRole","Resource":"arn:aws:iam::111122223333:role/app"},
+				{"Effect":"Allow","Action":"ec2:RunInstances","Resource":"*"}
+			]}`,
+			rule:     PolicyFindingPrivilegeEscalation,
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			findings, err := LintPolicyDocument("policy", test.policy)
+
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, test.expected, findingsWithRule(findings, test.rule))
+		})
+	}
+}
+
+func TestLintPolicyDocumentStatementIDs(t *testing.T) {
+	findings, err := LintPolicyDocument("policy", `{"Statement":[
+		{"Sid":"Named","Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::b"},
+		{"Effect":"Allow","Action":"ec2:*","Resource":"arn:aws:ec2:::instance/i-1"}
+	]}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PolicyFinding{
+		{Rule: PolicyFindingWildcardAction, Policy: "policy", Statement: "Named", Detail: "action s3:*"},
+		{Rule: PolicyFindingWildcardAction, Policy: "policy", Statement: "Statement[1]", Detail: "action ec2:*"},
+	}, findings)
+}
+
+func TestPolicyActionsMatch(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		action   string
+		match    bool
+	}{
+		{[]string{"*"}, "iam:PassRole", true},
+		{[]string{"iam:*"}, "iam:PassRole", true},
+		{[]string{"IAM:passrole"}, "iam:PassRole", true},
+		{[]string{"iam:Pass?ole"}, "iam:PassRole", true},
+		{[]string{"iam:Get*"}, "iam:PassRole", false},
+		{[]string{}, "iam:PassRole", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.match, policyActionsMatch(test.patterns, test.action), "%v %s", test.patterns, test.action)
+	}
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+// Role struct describing the complete expected configuration of an IAM role.
+// ManagedPolicyArns, InlinePolicies and Tags are exact sets, and an empty Description or PermissionsBoundaryArn
+// means the role must have none. Path defaults to "/" and MaxSessionDuration to 3600 seconds when left empty.
+// LastUsedWithin is not validated when zero
+type Role struct {
+	RoleName               string
+	Path                   string
+	Description            string
+	MaxSessionDuration     int64
+	PermissionsBoundaryArn string
+	ManagedPolicyArns      []string
+	InlinePolicies         map[string]string
+	Tags                   map[string]string
+	LastUsedWithin         time.Duration
+}
+
+// ValidateRole validates the attached managed policies, inline policies, permissions boundary, session duration,
+// path, description and tags of a role in a single comparison, so every difference is reported in one diff
+func ValidateRole(t *testing.T, svc *iam.IAM, expectedRole Role, verboseOutput bool) {
+	t.Helper()
+
+	roleResult, err := svc.GetRole(&iam.GetRoleInput{
+		RoleName: aws.String(expectedRole.RoleName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(roleResult.String())
+	}
+
+	role := roleResult.Role
+
+	actualRole := Role{
+		RoleName:           aws.StringValue(role.RoleName),
+		Path:               aws.StringValue(role.Path),
+		Description:        aws.StringValue(role.Description),
+		MaxSessionDuration: aws.Int64Value(role.MaxSessionDuration),
+		ManagedPolicyArns:  []string{},
+		InlinePolicies:     map[string]string{},
+		Tags:               map[string]string{},
+	}
+
+	if role.PermissionsBoundary != nil {
+		actualRole.PermissionsBoundaryArn = aws.StringValue(role.PermissionsBoundary.PermissionsBoundaryArn)
+	}
+
+	for _, tag := range role.Tags {
+		actualRole.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	err = svc.ListAttachedRolePoliciesPages(
+		&iam.ListAttachedRolePoliciesInput{RoleName: role.RoleName},
+		func(page *iam.ListAttachedRolePoliciesOutput, lastPage bool) bool {
+			for _, policy := range page.AttachedPolicies {
+				actualRole.ManagedPolicyArns = append(actualRole.ManagedPolicyArns, aws.StringValue(policy.PolicyArn))
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	err = svc.ListRolePoliciesPages(
+		&iam.ListRolePoliciesInput{RoleName: role.RoleName},
+		func(page *iam.ListRolePoliciesOutput, lastPage bool) bool {
+			for _, policyName := range page.PolicyNames {
+				document := GetRoleInlinePolicyDocument(t, svc, aws.StringValue(role.RoleName), aws.StringValue(policyName), verboseOutput)
+
+				normalized, err := normalizePolicyDocument(document)
+				if err != nil {
+					// keep the raw document so the diff still shows it
+					normalized = document
+				}
+
+				actualRole.InlinePolicies[aws.StringValue(policyName)] = normalized
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	normalizedExpectedRole, err := normalizeRole(expectedRole)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	sort.Strings(actualRole.ManagedPolicyArns)
+
+	// LastUsedWithin is validated separately against the last used date
+	normalizedExpectedRole.LastUsedWithin = 0
+
+	assert.Equal(t, normalizedExpectedRole, actualRole, "role %s", expectedRole.RoleName)
+
+	if expectedRole.LastUsedWithin > 0 {
+		if role.RoleLastUsed == nil || role.RoleLastUsed.LastUsedDate == nil {
+			assert.Fail(t, "role never used", "role %s has no last used date", expectedRole.RoleName)
+		} else {
+			assert.WithinDuration(t, time.Now(), aws.TimeValue(role.RoleLastUsed.LastUsedDate), expectedRole.LastUsedWithin, "last use of role %s", expectedRole.RoleName)
+		}
+	}
+}
+
+// normalizeRole applies the role defaults and rewrites collections and policy documents in the form ValidateRole compares
+func normalizeRole(role Role) (Role, error) {
+	normalized := role
+
+	if normalized.Path == "" {
+		normalized.Path = "/"
+	}
+
+	if normalized.MaxSessionDuration == 0 {
+		normalized.MaxSessionDuration = 3600
+	}
+
+	normalized.ManagedPolicyArns = append([]string{}, role.ManagedPolicyArns...)
+	sort.Strings(normalized.ManagedPolicyArns)
+
+	normalized.InlinePolicies = map[string]string{}
+
+	for policyName, document := range role.InlinePolicies {
+		normalizedDocument, err := normalizePolicyDocument(document)
+		if err != nil {
+			return Role{}, fmt.Errorf("inline policy %s: %w", policyName, err)
+		}
+
+		normalized.InlinePolicies[policyName] = normalizedDocument
+	}
+
+	normalized.Tags = map[string]string{}
+	for key, value := range role.Tags {
+		normalized.Tags[key] = value
+	}
+
+	return normalized, nil
+}
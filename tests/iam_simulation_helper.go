@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+// PolicySimulation struct describing one request to run through the IAM policy simulator and the decision it should get.
+// Resource defaults to "*" when empty
+type PolicySimulation struct {
+	Action         string
+	Resource       string
+	ContextEntries []PolicySimulationContextEntry
+	Allowed        bool
+}
+
+// PolicySimulationContextEntry struct describing a condition key value supplied to the simulator, such as aws:SourceIp.
+// Type takes one of the iam.ContextKeyTypeEnum values and defaults to string, or stringList when several values are given
+type PolicySimulationContextEntry struct {
+	Key    string
+	Type   string
+	Values []string
+}
+
+// ValidatePrincipalPolicySimulation simulates each request against the policies of a user, group or role and
+// reports every request whose decision differs from the expected one
+func ValidatePrincipalPolicySimulation(t *testing.T, svc *iam.IAM, principalArn string, simulations []PolicySimulation, verboseOutput bool) {
+	t.Helper()
+
+	for _, simulation := range simulations {
+		results := []*iam.EvaluationResult{}
+
+		err := svc.SimulatePrincipalPolicyPages(
+			&iam.SimulatePrincipalPolicyInput{
+				PolicySourceArn: aws.String(principalArn),
+				ActionNames:     []*string{aws.String(simulation.Action)},
+				ResourceArns:    []*string{aws.String(simulationResource(simulation))},
+				ContextEntries:  simulationContextEntries(simulation),
+			},
+			func(page *iam.SimulatePolicyResponse, lastPage bool) bool {
+				results = append(results, page.EvaluationResults...)
+
+				return true
+			},
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		validateSimulationResults(t, principalArn, simulation, results, verboseOutput)
+	}
+}
+
+// ValidateCustomPolicySimulation simulates each request against a set of policy documents that are not attached
+// to any principal, and reports every request whose decision differs from the expected one
+func ValidateCustomPolicySimulation(t *testing.T, svc *iam.IAM, policyDocuments []string, simulations []PolicySimulation, verboseOutput bool) {
+	t.Helper()
+
+	for _, simulation := range simulations {
+		results := []*iam.EvaluationResult{}
+
+		err := svc.SimulateCustomPolicyPages(
+			&iam.SimulateCustomPolicyInput{
+				PolicyInputList: aws.StringSlice(policyDocuments),
+				ActionNames:     []*string{aws.String(simulation.Action)},
+				ResourceArns:    []*string{aws.String(simulationResource(simulation))},
+				ContextEntries:  simulationContextEntries(simulation),
+			},
+			func(page *iam.SimulatePolicyResponse, lastPage bool) bool {
+				results = append(results, page.EvaluationResults...)
+
+				return true
+			},
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		validateSimulationResults(t, "custom policies", simulation, results, verboseOutput)
+	}
+}
+
+// validateSimulationResults compares the simulator decision with the expected one, describing the matched statements on a mismatch
+func validateSimulationResults(t *testing.T, source string, simulation PolicySimulation, results []*iam.EvaluationResult, verboseOutput bool) {
+	t.Helper()
+
+	if !assert.NotEmpty(t, results, "no simulation result for %s on %s", simulation.Action, simulationResource(simulation)) {
+		return
+	}
+
+	for _, result := range results {
+		if verboseOutput {
+			fmt.Println(result.String())
+		}
+
+		decision := aws.StringValue(result.EvalDecision)
+		allowed := decision == iam.PolicyEvaluationDecisionTypeAllowed
+
+		if allowed == simulation.Allowed {
+			continue
+		}
+
+		expected := "denied"
+		if simulation.Allowed {
+			expected = "allowed"
+		}
+
+		assert.Fail(
+			t,
+			"unexpected policy simulation decision",
+			"%s: %s on %s was %s, expected %s. Matched statements: %s. Missing context values: %v",
+			source,
+			simulation.Action,
+			aws.StringValue(result.EvalResourceName),
+			decision,
+			expected,
+			describeMatchedStatements(result.MatchedStatements),
+			aws.StringValueSlice(result.MissingContextValues),
+		)
+	}
+}
+
+// describeMatchedStatements renders the statements the simulator matched as policy id and position
+func describeMatchedStatements(statements []*iam.Statement) string {
+	if len(statements) == 0 {
+		return "none"
+	}
+
+	descriptions := []string{}
+
+	for _, statement := range statements {
+		description := fmt.Sprintf("%s (%s)", aws.StringValue(statement.SourcePolicyId), aws.StringValue(statement.SourcePolicyType))
+
+		if statement.StartPosition != nil {
+			description += fmt.Sprintf(" line %d column %d", aws.Int64Value(statement.StartPosition.Line), aws.Int64Value(statement.StartPosition.Column))
+		}
+
+		descriptions = append(descriptions, description)
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// simulationResource returns the resource to simulate against, defaulting to every resource
+func simulationResource(simulation PolicySimulation) string {
+	if simulation.Resource == "" {
+		return "*"
+	}
+
+	return simulation.Resource
+}
+
+// simulationContextEntries converts the context entries of a simulation to the simulator input
+func simulationContextEntries(simulation PolicySimulation) []*iam.ContextEntry {
+	if len(simulation.ContextEntries) == 0 {
+		return nil
+	}
+
+	entries := []*iam.ContextEntry{}
+
+	for _, entry := range simulation.ContextEntries {
+		keyType := entry.Type
+		if keyType == "" {
+			keyType = iam.ContextKeyTypeEnumString
+			if len(entry.Values) > 1 {
+				keyType = iam.ContextKeyTypeEnumStringList
+			}
+		}
+
+		entries = append(entries, &iam.ContextEntry{
+			ContextKeyName:   aws.String(entry.Key),
+			ContextKeyType:   aws.String(keyType),
+			ContextKeyValues: aws.StringSlice(entry.Values),
+		})
+	}
+
+	return entries
+}
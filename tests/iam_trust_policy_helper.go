@@ -0,0 +1,368 @@
+package tests
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+// GitHubActionsOIDCProviderHost is the host of the OIDC provider GitHub Actions workflows federate through
+const GitHubActionsOIDCProviderHost = "token.actions.githubusercontent.com"
+
+// accountRootArnPattern matches an account root principal ARN in any partition, which IAM stores for a principal written as a bare account ID
+var accountRootArnPattern = regexp.MustCompile(`^arn:[a-z-]+:iam::(\d{12}):root$`)
+
+// TrustPolicyStatement struct describing one statement of a role trust policy.
+// Principals is keyed by principal type (AWS, Service, Federated) and Conditions by operator then condition key
+type TrustPolicyStatement struct {
+	Sid        string
+	Effect     string
+	Principals map[string][]string
+	Actions    []string
+	Conditions map[string]map[string][]string
+}
+
+// TrustedPrincipals struct describing the exact principals a role trust policy allows to assume the role.
+// Accounts may be given as account IDs or root ARNs, which are compared on the account ID so any partition works
+type TrustedPrincipals struct {
+	AWS       []string
+	Service   []string
+	Federated []string
+}
+
+// GetRoleTrustPolicy returns the statements of the trust policy of a role
+func GetRoleTrustPolicy(t *testing.T, svc *iam.IAM, roleName string, verboseOutput bool) []TrustPolicyStatement {
+	t.Helper()
+
+	roleResult, err := svc.GetRole(&iam.GetRoleInput{
+		RoleName: aws.String(roleName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return nil
+	}
+
+	statements, err := parseTrustPolicy(aws.StringValue(roleResult.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return nil
+	}
+
+	if verboseOutput {
+		for _, statement := range statements {
+			fmt.Printf("%+v\n", statement)
+		}
+	}
+
+	return statements
+}
+
+// ValidateRoleTrustedPrincipals validates the exact set of principals the Allow statements of a role trust policy name
+func ValidateRoleTrustedPrincipals(t *testing.T, svc *iam.IAM, roleName string, expectedPrincipals TrustedPrincipals, verboseOutput bool) {
+	t.Helper()
+
+	statements := GetRoleTrustPolicy(t, svc, roleName, verboseOutput)
+	if statements == nil {
+		return
+	}
+
+	actual := trustedPrincipals(statements)
+
+	assert.ElementsMatch(t, normalizeTrustPrincipals(expectedPrincipals.AWS), actual["AWS"], "AWS principals trusted by role %s", roleName)
+	assert.ElementsMatch(t, expectedPrincipals.Service, actual["Service"], "service principals trusted by role %s", roleName)
+	assert.ElementsMatch(t, expectedPrincipals.Federated, actual["Federated"], "federated principals trusted by role %s", roleName)
+}
+
+// trustedPrincipals returns the unique principals named by the Allow statements, keyed by principal type
+func trustedPrincipals(statements []TrustPolicyStatement) map[string][]string {
+	principalsByType := map[string][]string{}
+
+	for _, statement := range statements {
+		if statement.Effect != "Allow" {
+			continue
+		}
+
+		for principalType, principals := range statement.Principals {
+			principalsByType[principalType] = append(principalsByType[principalType], principals...)
+		}
+	}
+
+	// the same principal is often named by several statements, such as one for sts:AssumeRole and one for sts:TagSession
+	for principalType, principals := range principalsByType {
+		principalsByType[principalType] = uniqueStrings(principals)
+	}
+
+	return principalsByType
+}
+
+// ValidateRoleTrustActions validates the exact set of sts actions the Allow statements of a role trust policy grant
+func ValidateRoleTrustActions(t *testing.T, svc *iam.IAM, roleName string, expectedActions []string, verboseOutput bool) {
+	t.Helper()
+
+	statements := GetRoleTrustPolicy(t, svc, roleName, verboseOutput)
+	if statements == nil {
+		return
+	}
+
+	actual := []string{}
+
+	for _, statement := range statements {
+		if statement.Effect == "Allow" {
+			actual = append(actual, statement.Actions...)
+		}
+	}
+
+	assert.ElementsMatch(t, expectedActions, uniqueStrings(actual), "actions trusted by role %s", roleName)
+}
+
+// ValidateRoleTrustCondition validates that every Allow statement trusting the principal requires the condition key
+// under the operator with exactly the expected values
+func ValidateRoleTrustCondition(t *testing.T, svc *iam.IAM, roleName string, principal string, operator string, key string, expectedValues []string, verboseOutput bool) {
+	t.Helper()
+
+	statements := GetRoleTrustPolicy(t, svc, roleName, verboseOutput)
+	if statements == nil {
+		return
+	}
+
+	validateTrustCondition(t, roleName, trustingStatements(statements, principal), []string{operator}, key, expectedValues)
+}
+
+// ValidateRoleTrustRequiresExternalID validates that every Allow statement trusting an AWS principal requires the sts:ExternalId
+func ValidateRoleTrustRequiresExternalID(t *testing.T, svc *iam.IAM, roleName string, externalID string, verboseOutput bool) {
+	t.Helper()
+
+	statements := GetRoleTrustPolicy(t, svc, roleName, verboseOutput)
+	if statements == nil {
+		return
+	}
+
+	validateTrustCondition(t, roleName, statementsWithPrincipalType(statements, "AWS"), []string{"StringEquals"}, "sts:ExternalId", []string{externalID})
+}
+
+// ValidateRoleTrustRequiresPrincipalOrgID validates that every Allow statement trusting an AWS principal is restricted to an organization
+func ValidateRoleTrustRequiresPrincipalOrgID(t *testing.T, svc *iam.IAM, roleName string, organizationID string, verboseOutput bool) {
+	t.Helper()
+
+	statements := GetRoleTrustPolicy(t, svc, roleName, verboseOutput)
+	if statements == nil {
+		return
+	}
+
+	validateTrustCondition(t, roleName, statementsWithPrincipalType(statements, "AWS"), []string{"StringEquals"}, "aws:PrincipalOrgID", []string{organizationID})
+}
+
+// ValidateRoleTrustGitHubActionsSubjects validates that every Allow statement trusting the GitHub Actions OIDC provider
+// requires the sts.amazonaws.com audience and restricts the sub claim to exactly the expected subjects, such as
+// repo:my-org/my-repo:ref:refs/heads/main
+func ValidateRoleTrustGitHubActionsSubjects(t *testing.T, svc *iam.IAM, roleName string, expectedSubjects []string, verboseOutput bool) {
+	t.Helper()
+
+	statements := GetRoleTrustPolicy(t, svc, roleName, verboseOutput)
+	if statements == nil {
+		return
+	}
+
+	gitHubStatements := []TrustPolicyStatement{}
+
+	for _, statement := range statementsWithPrincipalType(statements, "Federated") {
+		for _, provider := range statement.Principals["Federated"] {
+			if strings.HasSuffix(provider, "oidc-provider/"+GitHubActionsOIDCProviderHost) {
+				gitHubStatements = append(gitHubStatements, statement)
+
+				break
+			}
+		}
+	}
+
+	if !assert.NotEmpty(t, gitHubStatements, "role %s does not trust the GitHub Actions OIDC provider", roleName) {
+		return
+	}
+
+	validateTrustCondition(t, roleName, gitHubStatements, []string{"StringEquals"}, GitHubActionsOIDCProviderHost+":aud", []string{"sts.amazonaws.com"})
+	validateTrustCondition(t, roleName, gitHubStatements, []string{"StringEquals", "StringLike"}, GitHubActionsOIDCProviderHost+":sub", expectedSubjects)
+}
+
+// validateTrustCondition validates that each statement carries the condition key under one of the operators with exactly the expected values
+func validateTrustCondition(t *testing.T, roleName string, statements []TrustPolicyStatement, operators []string, key string, expectedValues []string) {
+	t.Helper()
+
+	if !assert.NotEmpty(t, statements, "role %s has no trust statement to check %s against", roleName, key) {
+		return
+	}
+
+	for _, statement := range statements {
+		values, found := trustConditionValues(statement, operators, key)
+		if !found {
+			assert.Fail(t, "trust condition missing", "statement %q of role %s does not require %s with %v", statement.Sid, roleName, key, operators)
+
+			continue
+		}
+
+		assert.ElementsMatch(t, expectedValues, values, "%s of statement %q of role %s", key, statement.Sid, roleName)
+	}
+}
+
+// trustConditionValues returns the values of a condition key under any of the operators. Condition keys are case insensitive
+func trustConditionValues(statement TrustPolicyStatement, operators []string, key string) ([]string, bool) {
+	for _, operator := range operators {
+		for conditionKey, values := range statement.Conditions[operator] {
+			if strings.EqualFold(conditionKey, key) {
+				return values, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// trustingStatements returns the Allow statements that name the principal
+func trustingStatements(statements []TrustPolicyStatement, principal string) []TrustPolicyStatement {
+	principal = normalizeTrustPrincipals([]string{principal})[0]
+	matching := []TrustPolicyStatement{}
+
+	for _, statement := range statements {
+		if statement.Effect != "Allow" {
+			continue
+		}
+
+		trusted := false
+
+		for _, principals := range statement.Principals {
+			for _, statementPrincipal := range principals {
+				trusted = trusted || statementPrincipal == principal
+			}
+		}
+
+		if trusted {
+			matching = append(matching, statement)
+		}
+	}
+
+	return matching
+}
+
+// statementsWithPrincipalType returns the Allow statements that name a principal of the given type
+func statementsWithPrincipalType(statements []TrustPolicyStatement, principalType string) []TrustPolicyStatement {
+	matching := []TrustPolicyStatement{}
+
+	for _, statement := range statements {
+		if statement.Effect == "Allow" && len(statement.Principals[principalType]) > 0 {
+			matching = append(matching, statement)
+		}
+	}
+
+	return matching
+}
+
+// parseTrustPolicy decodes a trust policy document into its statements
+func parseTrustPolicy(policyJSON string) ([]TrustPolicyStatement, error) {
+	document, err := decodePolicyDocument(policyJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := policyStatements(document)
+	if err != nil {
+		return nil, err
+	}
+
+	trustStatements := []TrustPolicyStatement{}
+
+	for i, statement := range statements {
+		trustStatement := TrustPolicyStatement{
+			Principals: map[string][]string{},
+			Actions:    sortedPolicyValues(statement["Action"]),
+			Conditions: map[string]map[string][]string{},
+		}
+
+		trustStatement.Effect, _ = statement["Effect"].(string)
+
+		trustStatement.Sid, _ = statement["Sid"].(string)
+		if trustStatement.Sid == "" {
+			trustStatement.Sid = fmt.Sprintf("Statement[%d]", i)
+		}
+
+		switch principal := statement["Principal"].(type) {
+		case string:
+			trustStatement.Principals["AWS"] = []string{principal}
+		case map[string]interface{}:
+			for principalType, value := range principal {
+				trustStatement.Principals[principalType] = normalizeTrustPrincipals(sortedPolicyValues(value))
+			}
+		}
+
+		if conditions, ok := statement["Condition"].(map[string]interface{}); ok {
+			for operator, keys := range conditions {
+				keyMap, ok := keys.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("invalid condition %v", keys)
+				}
+
+				trustStatement.Conditions[operator] = map[string][]string{}
+
+				for key, values := range keyMap {
+					trustStatement.Conditions[operator][key] = sortedPolicyValues(values)
+				}
+			}
+		}
+
+		trustStatements = append(trustStatements, trustStatement)
+	}
+
+	return trustStatements, nil
+}
+
+// normalizeTrustPrincipals rewrites account root ARNs as the bare account ID, so principals compare the same in every partition
+func normalizeTrustPrincipals(principals []string) []string {
+	normalized := []string{}
+
+	for _, principal := range principals {
+		if match := accountRootArnPattern.FindStringSubmatch(principal); match != nil {
+			principal = match[1]
+		}
+
+		normalized = append(normalized, principal)
+	}
+
+	return normalized
+}
+
+// uniqueStrings returns the values without duplicates, keeping their first occurrence order
+func uniqueStrings(values []string) []string {
+	unique := []string{}
+	seen := map[string]bool{}
+
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
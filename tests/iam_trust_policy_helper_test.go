@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedPrincipals(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		expected map[string][]string
+	}{
+		{
+			name: "duplicate principals across statements",
+			policy: `{"Statement":[
+				{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::111122223333:root"},"Action":"sts:AssumeRole"},
+				{"Effect":"Allow","Principal":{"AWS":["111122223333","arn:aws:iam::111122223333:role/app"]},"Action":"sts:TagSession"}
+			]}`,
+			expected: map[string][]string{"AWS": {"111122223333", "arn:aws:iam::111122223333:role/app"}},
+		},
+		{
+			name: "root ARNs in other partitions",
+			policy: `{"Statement":[
+				{"Effect":"Allow","Principal":{"AWS":["arn:aws-us-gov:iam::111122223333:root","arn:aws-cn:iam::444455556666:root"]},"Action":"sts:AssumeRole"}
+			]}`,
+			expected: map[string][]string{"AWS": {"111122223333", "444455556666"}},
+		},
+		{
+			name: "deny statements are ignored",
+			policy: `{"Statement":[
+				{"Effect":"Allow","Principal":{"Service":["ec2.amazonaws.com","ec2.amazonaws.com"]},"Action":"sts:AssumeRole"},
+				{"Effect":"Deny","Principal":{"AWS":"arn:aws:iam::444455556666:root"},"Action":"sts:AssumeRole"}
+			]}`,
+			expected: map[string][]string{"Service": {"ec2.amazonaws.com"}},
+		},
+		{
+			name:     "wildcard principal",
+			policy:   `{"Statement":{"Effect":"Allow","Principal":"*","Action":"sts:AssumeRole"}}`,
+			expected: map[string][]string{"AWS": {"*"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statements, err := parseTrustPolicy(test.policy)
+
+			assert.NoError(t, err)
+
+			actual := trustedPrincipals(statements)
+			assert.Len(t, actual, len(test.expected))
+
+			for principalType, principals := range test.expected {
+				assert.ElementsMatch(t, principals, actual[principalType], principalType)
+			}
+		})
+	}
+}
+
+func TestNormalizeTrustPrincipals(t *testing.T) {
+	tests := map[string]string{
+		"111122223333":                             "111122223333",
+		"arn:aws:iam::111122223333:root":           "111122223333",
+		"arn:aws-us-gov:iam::111122223333:root":    "111122223333",
+		"arn:aws-cn:iam::111122223333:root":        "111122223333",
+		"arn:aws:iam::111122223333:role/app":       "arn:aws:iam::111122223333:role/app",
+		"arn:aws:iam::111122223333:user/root":      "arn:aws:iam::111122223333:user/root",
+		"arn:aws:sts::111122223333:assumed-role/a": "arn:aws:sts::111122223333:assumed-role/a",
+	}
+
+	for principal, expected := range tests {
+		assert.Equal(t, []string{expected}, normalizeTrustPrincipals([]string{principal}), principal)
+	}
+}
+
+func TestParseTrustPolicyConditions(t *testing.T) {
+	statements, err := parseTrustPolicy(`{"Statement":[
+		{"Sid":"GitHub","Effect":"Allow","Principal":{"Federated":"arn:aws:iam::111122223333:oidc-provider/token.actions.githubusercontent.com"},"Action":"sts:AssumeRoleWithWebIdentity",
+			"Condition":{"StringEquals":{"token.actions.githubusercontent.com:aud":"sts.amazonaws.com"},"StringLike":{"token.actions.githubusercontent.com:sub":["repo:o/r:ref:refs/heads/main","repo:o/r:environment:prod"]}}},
+		{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::444455556666:root"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"STS:ExternalID":"secret"}}}
+	]}`)
+	assert.NoError(t, err)
+
+	if !assert.Len(t, statements, 2) {
+		return
+	}
+
+	assert.Equal(t, "GitHub", statements[0].Sid)
+	assert.Equal(t, "Statement[1]", statements[1].Sid)
+
+	values, found := trustConditionValues(statements[0], []string{"StringEquals", "StringLike"}, GitHubActionsOIDCProviderHost+":sub")
+	assert.True(t, found)
+	assert.ElementsMatch(t, []string{"repo:o/r:ref:refs/heads/main", "repo:o/r:environment:prod"}, values)
+
+	values, found = trustConditionValues(statements[1], []string{"StringEquals"}, "sts:ExternalId")
+	assert.True(t, found)
+	assert.Equal(t, []string{"secret"}, values)
+
+	_, found = trustConditionValues(statements[1], []string{"StringLike"}, "sts:ExternalId")
+	assert.False(t, found)
+
+	assert.Equal(t, []TrustPolicyStatement{statements[1]}, trustingStatements(statements, "arn:aws:iam::444455556666:root"))
+	assert.Equal(t, []TrustPolicyStatement{statements[1]}, statementsWithPrincipalType(statements, "AWS"))
+	assert.Equal(t, []TrustPolicyStatement{statements[0]}, statementsWithPrincipalType(statements, "Federated"))
+}
+
+func TestParseTrustPolicyInvalid(t *testing.T) {
+	for _, policy := range []string{`{"Statement":`, `{"Statement":{"Effect":"Allow","Condition":{"StringEquals":"x"}}}`} {
+		_, err := parseTrustPolicy(policy)
+
+		assert.Error(t, err, policy)
+	}
+}
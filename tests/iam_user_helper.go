@@ -0,0 +1,269 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+// UserPosture struct describing the security posture expected of an IAM user, such as a break-glass or service user.
+// Nil fields and zero durations are not validated. Non-nil slices are exact sets, and an empty PermissionsBoundaryArn
+// means the user must have none. Only active access keys count towards MaxActiveAccessKeys, MaxAccessKeyAge and
+// MaxAccessKeyIdle, and a key that was never used is idle since it was created. MaxPasswordIdle only applies to a user with a
+// console password, and a password that was never used is idle since its login profile was created
+type UserPosture struct {
+	UserName               string
+	ConsoleAccess          *bool
+	MFAEnabled             *bool
+	MaxActiveAccessKeys    *int
+	MaxAccessKeyAge        time.Duration
+	MaxAccessKeyIdle       time.Duration
+	MaxPasswordIdle        time.Duration
+	InlinePolicyNames      []string
+	ManagedPolicyArns      []string
+	Groups                 []string
+	PermissionsBoundaryArn *string
+}
+
+// ValidateUserPosture validates the console access, MFA devices, access keys, policies, group memberships and
+// permissions boundary of a user
+func ValidateUserPosture(t *testing.T, svc *iam.IAM, expectedPosture UserPosture, verboseOutput bool) {
+	t.Helper()
+
+	userName := aws.String(expectedPosture.UserName)
+
+	userResult, err := svc.GetUser(&iam.GetUserInput{UserName: userName})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				fmt.Println(iam.ErrCodeNoSuchEntityException, aerr.Error())
+			case iam.ErrCodeServiceFailureException:
+				fmt.Println(iam.ErrCodeServiceFailureException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(userResult.String())
+	}
+
+	user := userResult.User
+
+	if expectedPosture.ConsoleAccess != nil || expectedPosture.MaxPasswordIdle > 0 {
+		var loginProfile *iam.LoginProfile
+
+		loginProfileResult, err := svc.GetLoginProfile(&iam.GetLoginProfileInput{UserName: userName})
+		if err != nil {
+			// a user without console access has no login profile
+			if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != iam.ErrCodeNoSuchEntityException {
+				fmt.Println(err.Error())
+				t.Logf("Failing test.")
+				t.Fail()
+
+				return
+			}
+		} else {
+			loginProfile = loginProfileResult.LoginProfile
+		}
+
+		if expectedPosture.ConsoleAccess != nil {
+			assert.Equal(t, *expectedPosture.ConsoleAccess, loginProfile != nil, "console access of user %s", expectedPosture.UserName)
+		}
+
+		if expectedPosture.MaxPasswordIdle > 0 && loginProfile != nil {
+			// a password that was never used is idle since its login profile was created
+			passwordLastUsed := aws.TimeValue(loginProfile.CreateDate)
+			if user.PasswordLastUsed != nil {
+				passwordLastUsed = aws.TimeValue(user.PasswordLastUsed)
+			}
+
+			assert.WithinDuration(t, time.Now(), passwordLastUsed, expectedPosture.MaxPasswordIdle, "password last used by user %s", expectedPosture.UserName)
+		}
+	}
+
+	if expectedPosture.MFAEnabled != nil {
+		mfaDevices := 0
+
+		err := svc.ListMFADevicesPages(
+			&iam.ListMFADevicesInput{UserName: userName},
+			func(page *iam.ListMFADevicesOutput, lastPage bool) bool {
+				mfaDevices += len(page.MFADevices)
+
+				return true
+			},
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		assert.Equal(t, *expectedPosture.MFAEnabled, mfaDevices > 0, "MFA devices of user %s", expectedPosture.UserName)
+	}
+
+	if expectedPosture.MaxActiveAccessKeys != nil || expectedPosture.MaxAccessKeyAge > 0 || expectedPosture.MaxAccessKeyIdle > 0 {
+		validateUserAccessKeys(t, svc, expectedPosture, verboseOutput)
+	}
+
+	if expectedPosture.InlinePolicyNames != nil {
+		inlinePolicyNames := []string{}
+
+		err := svc.ListUserPoliciesPages(
+			&iam.ListUserPoliciesInput{UserName: userName},
+			func(page *iam.ListUserPoliciesOutput, lastPage bool) bool {
+				inlinePolicyNames = append(inlinePolicyNames, aws.StringValueSlice(page.PolicyNames)...)
+
+				return true
+			},
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		assert.ElementsMatch(t, expectedPosture.InlinePolicyNames, inlinePolicyNames, "inline policies of user %s", expectedPosture.UserName)
+	}
+
+	if expectedPosture.ManagedPolicyArns != nil {
+		managedPolicyArns := []string{}
+
+		err := svc.ListAttachedUserPoliciesPages(
+			&iam.ListAttachedUserPoliciesInput{UserName: userName},
+			func(page *iam.ListAttachedUserPoliciesOutput, lastPage bool) bool {
+				for _, policy := range page.AttachedPolicies {
+					managedPolicyArns = append(managedPolicyArns, aws.StringValue(policy.PolicyArn))
+				}
+
+				return true
+			},
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		assert.ElementsMatch(t, expectedPosture.ManagedPolicyArns, managedPolicyArns, "managed policies attached to user %s", expectedPosture.UserName)
+	}
+
+	if expectedPosture.Groups != nil {
+		groups := []string{}
+
+		err := svc.ListGroupsForUserPages(
+			&iam.ListGroupsForUserInput{UserName: userName},
+			func(page *iam.ListGroupsForUserOutput, lastPage bool) bool {
+				for _, group := range page.Groups {
+					groups = append(groups, aws.StringValue(group.GroupName))
+				}
+
+				return true
+			},
+		)
+		if err != nil {
+			fmt.Println(err.Error())
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		assert.ElementsMatch(t, expectedPosture.Groups, groups, "groups of user %s", expectedPosture.UserName)
+	}
+
+	if expectedPosture.PermissionsBoundaryArn != nil {
+		permissionsBoundaryArn := ""
+		if user.PermissionsBoundary != nil {
+			permissionsBoundaryArn = aws.StringValue(user.PermissionsBoundary.PermissionsBoundaryArn)
+		}
+
+		assert.Equal(t, *expectedPosture.PermissionsBoundaryArn, permissionsBoundaryArn, "permissions boundary of user %s", expectedPosture.UserName)
+	}
+}
+
+// validateUserAccessKeys validates the number, age and last use of the active access keys of a user
+func validateUserAccessKeys(t *testing.T, svc *iam.IAM, expectedPosture UserPosture, verboseOutput bool) {
+	t.Helper()
+
+	activeAccessKeys := []*iam.AccessKeyMetadata{}
+
+	err := svc.ListAccessKeysPages(
+		&iam.ListAccessKeysInput{UserName: aws.String(expectedPosture.UserName)},
+		func(page *iam.ListAccessKeysOutput, lastPage bool) bool {
+			for _, accessKey := range page.AccessKeyMetadata {
+				if aws.StringValue(accessKey.Status) == iam.StatusTypeActive {
+					activeAccessKeys = append(activeAccessKeys, accessKey)
+				}
+			}
+
+			return true
+		},
+	)
+	if err != nil {
+		fmt.Println(err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if expectedPosture.MaxActiveAccessKeys != nil {
+		assert.LessOrEqual(t, len(activeAccessKeys), *expectedPosture.MaxActiveAccessKeys, "active access keys of user %s", expectedPosture.UserName)
+	}
+
+	now := time.Now()
+
+	for _, accessKey := range activeAccessKeys {
+		accessKeyID := aws.StringValue(accessKey.AccessKeyId)
+		createDate := aws.TimeValue(accessKey.CreateDate)
+
+		if expectedPosture.MaxAccessKeyAge > 0 {
+			assert.WithinDuration(t, now, createDate, expectedPosture.MaxAccessKeyAge, "age of access key %s of user %s", accessKeyID, expectedPosture.UserName)
+		}
+
+		if expectedPosture.MaxAccessKeyIdle == 0 {
+			continue
+		}
+
+		lastUsedResult, err := svc.GetAccessKeyLastUsed(&iam.GetAccessKeyLastUsedInput{AccessKeyId: accessKey.AccessKeyId})
+		if err != nil {
+			fmt.Println(err.Error())
+			t.Logf("Failing test.")
+			t.Fail()
+
+			continue
+		}
+
+		if verboseOutput {
+			fmt.Println(lastUsedResult.String())
+		}
+
+		lastUsed := createDate
+		if lastUsedResult.AccessKeyLastUsed != nil && lastUsedResult.AccessKeyLastUsed.LastUsedDate != nil {
+			lastUsed = aws.TimeValue(lastUsedResult.AccessKeyLastUsed.LastUsedDate)
+		}
+
+		assert.WithinDuration(t, now, lastUsed, expectedPosture.MaxAccessKeyIdle, "last use of access key %s of user %s", accessKeyID, expectedPosture.UserName)
+	}
+}
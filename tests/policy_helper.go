@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// policyListElements are the policy statement elements whose value may be written as a single string or a list
+var policyListElements = map[string]bool{
+	"Action":      true,
+	"NotAction":   true,
+	"Resource":    true,
+	"NotResource": true,
+}
+
+// ValidatePolicyDocumentsEquivalent validates that two IAM style policy documents grant the same permissions.
+// Statement order, list order and the single string form of list values are ignored, so the comparison
+// matches the way AWS evaluates the policy rather than the exact JSON returned by the API
+func ValidatePolicyDocumentsEquivalent(t *testing.T, expectedPolicyJSON string, actualPolicyJSON string, verboseOutput bool) {
+	t.Helper()
+
+	expected, err := normalizePolicyDocument(expectedPolicyJSON)
+	if err != nil {
+		fmt.Println("expected policy:", err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	actual, err := normalizePolicyDocument(actualPolicyJSON)
+	if err != nil {
+		fmt.Println("actual policy:", err.Error())
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(expected)
+		fmt.Println(actual)
+	}
+
+	assert.JSONEq(t, expected, actual)
+}
+
+// normalizePolicyDocument decodes a policy document, which AWS may return URL encoded, and rewrites it in a canonical form
+func normalizePolicyDocument(policyJSON string) (string, error) {
+	document, err := decodePolicyDocument(policyJSON)
+	if err != nil {
+		return "", err
+	}
+
+	statements, err := policyStatements(document)
+	if err != nil {
+		return "", err
+	}
+
+	normalizedStatements := []string{}
+
+	for _, statementMap := range statements {
+		for key, value := range statementMap {
+			switch {
+			case policyListElements[key]:
+				statementMap[key] = sortedPolicyValues(value)
+			case key == "Principal" || key == "NotPrincipal" || key == "Condition":
+				statementMap[key] = normalizePolicyMap(value)
+			}
+		}
+
+		encoded, err := json.Marshal(statementMap)
+		if err != nil {
+			return "", err
+		}
+
+		normalizedStatements = append(normalizedStatements, string(encoded))
+	}
+
+	sort.Strings(normalizedStatements)
+
+	canonicalStatements := []json.RawMessage{}
+	for _, statement := range normalizedStatements {
+		canonicalStatements = append(canonicalStatements, json.RawMessage(statement))
+	}
+
+	document["Statement"] = canonicalStatements
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encoded), nil
+}
+
+// decodePolicyDocument parses a policy document, URL decoding it first when AWS returned it encoded
+func decodePolicyDocument(policyJSON string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(strings.TrimSpace(policyJSON), "{") {
+		decoded, err := url.QueryUnescape(policyJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		policyJSON = decoded
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal([]byte(policyJSON), &document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// policyStatements returns the statements of a decoded policy document, which may hold a single statement or a list
+func policyStatements(document map[string]interface{}) ([]map[string]interface{}, error) {
+	statements := []interface{}{}
+
+	switch statement := document["Statement"].(type) {
+	case []interface{}:
+		statements = statement
+	case map[string]interface{}:
+		statements = append(statements, statement)
+	}
+
+	statementMaps := []map[string]interface{}{}
+
+	for _, statement := range statements {
+		statementMap, ok := statement.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid policy statement %v", statement)
+		}
+
+		statementMaps = append(statementMaps, statementMap)
+	}
+
+	return statementMaps, nil
+}
+
+// normalizePolicyMap sorts the values of a Principal or Condition block, recursing into nested condition operators.
+// A wildcard principal written as "*" is left as is
+func normalizePolicyMap(value interface{}) interface{} {
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	for key, nested := range valueMap {
+		if nestedMap, isMap := nested.(map[string]interface{}); isMap {
+			valueMap[key] = normalizePolicyMap(nestedMap)
+		} else {
+			valueMap[key] = sortedPolicyValues(nested)
+		}
+	}
+
+	return valueMap
+}
+
+// sortedPolicyValues converts a policy value written as a string or a list into a sorted list of strings
+func sortedPolicyValues(value interface{}) []string {
+	values := []string{}
+
+	switch typed := value.(type) {
+	case []interface{}:
+		for _, item := range typed {
+			values = append(values, fmt.Sprint(item))
+		}
+	case nil:
+	default:
+		values = append(values, fmt.Sprint(typed))
+	}
+
+	sort.Strings(values)
+
+	return values
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePolicyDocumentEquivalent(t *testing.T) {
+	base := `{"Version":"2012-10-17","Statement":[
+		{"Sid":"Read","Effect":"Allow","Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::b","arn:aws:s3:::b/*"]},
+		{"Sid":"Deny","Effect":"Deny","Principal":{"AWS":["arn:aws:iam::111122223333:root","arn:aws:iam::444455556666:root"]},"Action":"s3:DeleteObject","Resource":"*","Condition":{"StringEquals":{"aws:PrincipalOrgID":["o-1","o-2"]}}}
+	]}`
+
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{
+			name: "statement order",
+			policy: `{"Version":"2012-10-17","Statement":[
+				{"Sid":"Deny","Effect":"Deny","Principal":{"AWS":["arn:aws:iam::111122223333:root","arn:aws:iam::444455556666:root"]},"Action":"s3:DeleteObject","Resource":"*","Condition":{"StringEquals":{"aws:PrincipalOrgID":["o-1","o-2"]}}},
+				{"Sid":"Read","Effect":"Allow","Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::b","arn:aws:s3:::b/*"]}
+			]}`,
+		},
+		{
+			name: "list order and single string values",
+			policy: `{"Version":"2012-10-17","Statement":[
+				{"Sid":"Read","Effect":"Allow","Action":["s3:ListBucket","s3:GetObject"],"Resource":["arn:aws:s3:::b/*","arn:aws:s3:::b"]},
+				{"Sid":"Deny","Effect":"Deny","Principal":{"AWS":["arn:aws:iam::444455556666:root","arn:aws:iam::111122223333:root"]},"Action":["s3:DeleteObject"],"Resource":["*"],"Condition":{"StringEquals":{"aws:PrincipalOrgID":["o-2","o-1"]}}}
+			]}`,
+		},
+		{
+			name:   "URL encoded",
+			policy: url.QueryEscape(base),
+		},
+	}
+
+	expected, err := normalizePolicyDocument(base)
+	assert.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := normalizePolicyDocument(test.policy)
+
+			assert.NoError(t, err)
+			assert.JSONEq(t, expected, actual)
+		})
+	}
+}
+
+func TestNormalizePolicyDocumentDifferent(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+	}{
+		{
+			name:     "different action",
+			expected: `{"Statement":{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}}`,
+			actual:   `{"Statement":{"Effect":"Allow","Action":"s3:PutObject","Resource":"*"}}`,
+		},
+		{
+			name:     "extra resource",
+			expected: `{"Statement":{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}}`,
+			actual:   `{"Statement":{"Effect":"Allow","Action":"s3:GetObject","Resource":["arn:aws:s3:::b/*","arn:aws:s3:::c/*"]}}`,
+		},
+		{
+			name:     "different effect",
+			expected: `{"Statement":{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}}`,
+			actual:   `{"Statement":{"Effect":"Deny","Action":"s3:GetObject","Resource":"*"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := normalizePolicyDocument(test.expected)
+			assert.NoError(t, err)
+
+			actual, err := normalizePolicyDocument(test.actual)
+			assert.NoError(t, err)
+
+			assert.NotEqual(t, expected, actual)
+		})
+	}
+}
+
+func TestNormalizePolicyDocumentInvalid(t *testing.T) {
+	for _, policy := range []string{`{"Statement":`, `{"Statement":["not a statement"]}`, `%zz`} {
+		_, err := normalizePolicyDocument(policy)
+
+		assert.Error(t, err, policy)
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// ValidateBucketLocation get bucket location
+// ValidateBucketLocation get bucket location and validates it is in the expected region
 func ValidateBucketLocation(t *testing.T, svc *s3.S3, bucketName string, region string, verboseOutput bool) {
 	t.Helper()
 
@@ -39,7 +39,32 @@ func ValidateBucketLocation(t *testing.T, svc *s3.S3, bucketName string, region
 	}
 
 	if verboseOutput {
-		fmt.Println(*getBucketLocationResult.LocationConstraint)
+		fmt.Println(getBucketLocationResult.String())
+	}
+
+	assert.Equal(t, region, NormalizeBucketRegion(aws.StringValue(getBucketLocationResult.LocationConstraint)), "bucket %s is not in the expected region", bucketName)
+}
+
+// ValidateBucketsLocation get the location of each bucket and validates it is in the expected region.
+// bucketRegions maps each bucket name to its expected region
+func ValidateBucketsLocation(t *testing.T, svc *s3.S3, bucketRegions map[string]string, verboseOutput bool) {
+	t.Helper()
+
+	for bucketName, region := range bucketRegions {
+		ValidateBucketLocation(t, svc, bucketName, region, verboseOutput)
+	}
+}
+
+// NormalizeBucketRegion converts a bucket LocationConstraint to a region name.
+// AWS returns an empty constraint for us-east-1 and EU for legacy eu-west-1 buckets
+func NormalizeBucketRegion(locationConstraint string) string {
+	switch locationConstraint {
+	case "":
+		return "us-east-1"
+	case s3.BucketLocationConstraintEu:
+		return "eu-west-1"
+	default:
+		return locationConstraint
 	}
 }
 
@@ -77,8 +102,54 @@ func ValidateBucketPolicy(t *testing.T, svc *s3.S3, bucketName string, policyJSO
 	assert.JSONEq(t, policyJSON, *getBucketPolicyResult.Policy)
 }
 
-// ValidateBucketACL get bucket acl
-func ValidateBucketACL(t *testing.T, svc *s3.S3, bucketName string, verboseOutput bool) {
+// S3 predefined group URIs that can appear as grantees on a bucket ACL
+const (
+	S3AllUsersGroupURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	S3AuthenticatedUsersGroupURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+	S3LogDeliveryGroupURI        = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+)
+
+// BucketACLGrant struct describing a single grant expected on a bucket ACL.
+// Grantee is the canonical user ID or the group URI depending on GranteeType. S3 stores a grant to an email address as a
+// CanonicalUser grant, so use the canonical user ID of the account instead of AmazonCustomerByEmail
+type BucketACLGrant struct {
+	GranteeType string
+	Grantee     string
+	Permission  string
+}
+
+// BucketACL struct describing the owner and the exact set of grants expected on a bucket ACL
+type BucketACL struct {
+	OwnerID string
+	Grants  []BucketACLGrant
+}
+
+// PrivateBucketACL returns the expected ACL of a bucket where only the owner has access
+func PrivateBucketACL(ownerID string) BucketACL {
+	return BucketACL{
+		OwnerID: ownerID,
+		Grants: []BucketACLGrant{
+			{GranteeType: s3.TypeCanonicalUser, Grantee: ownerID, Permission: s3.PermissionFullControl},
+		},
+	}
+}
+
+// LogDeliveryWriteBucketACL returns the expected ACL of a bucket using the log-delivery-write canned ACL
+func LogDeliveryWriteBucketACL(ownerID string) BucketACL {
+	return BucketACL{
+		OwnerID: ownerID,
+		Grants: []BucketACLGrant{
+			{GranteeType: s3.TypeCanonicalUser, Grantee: ownerID, Permission: s3.PermissionFullControl},
+			{GranteeType: s3.TypeGroup, Grantee: S3LogDeliveryGroupURI, Permission: s3.PermissionWrite},
+			{GranteeType: s3.TypeGroup, Grantee: S3LogDeliveryGroupURI, Permission: s3.PermissionReadAcp},
+		},
+	}
+}
+
+// ValidateBucketHasACL get bucket acl and validates one is returned, which is what ValidateBucketACL checked before it took the expected ACL
+//
+// Deprecated: ValidateBucketHasACL does not validate the grants. Use ValidateBucketACL instead.
+func ValidateBucketHasACL(t *testing.T, svc *s3.S3, bucketName string, verboseOutput bool) {
 	t.Helper()
 
 	getBucketACLInput := &s3.GetBucketAclInput{
@@ -89,8 +160,8 @@ func ValidateBucketACL(t *testing.T, svc *s3.S3, bucketName string, verboseOutpu
 	if err1 != nil {
 		if aerr, ok := err1.(awserr.Error); ok {
 			switch aerr.Code() {
-			case kms.ErrCodeNotFoundException:
-				fmt.Println(kms.ErrCodeNotFoundException, aerr.Error())
+			case s3.ErrCodeNoSuchBucket:
+				fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
 			default:
 				fmt.Println(aerr.Error())
 			}
@@ -111,6 +182,109 @@ func ValidateBucketACL(t *testing.T, svc *s3.S3, bucketName string, verboseOutpu
 	assert.NotEmpty(t, getBucketACLResult.String())
 }
 
+// ValidateBucketACL get bucket acl and validates the owner and the exact set of grants
+func ValidateBucketACL(t *testing.T, svc *s3.S3, bucketName string, expectedACL BucketACL, verboseOutput bool) {
+	t.Helper()
+
+	for _, grant := range expectedACL.Grants {
+		if grant.GranteeType == s3.TypeAmazonCustomerByEmail {
+			assert.Fail(t, "email grantees cannot be validated", "S3 stores the grant of %s to %s as a %s grant, so expect the canonical user ID instead", grant.Permission, grant.Grantee, s3.TypeCanonicalUser)
+
+			return
+		}
+	}
+
+	getBucketACLInput := &s3.GetBucketAclInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	getBucketACLResult, err1 := svc.GetBucketAcl(getBucketACLInput)
+	if err1 != nil {
+		if aerr, ok := err1.(awserr.Error); ok {
+			switch aerr.Code() {
+			case s3.ErrCodeNoSuchBucket:
+				fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err1.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(getBucketACLResult.String())
+	}
+
+	ownerID := ""
+	if getBucketACLResult.Owner != nil {
+		ownerID = aws.StringValue(getBucketACLResult.Owner.ID)
+	}
+
+	assert.Equal(t, expectedACL.OwnerID, ownerID)
+
+	actualGrants := []BucketACLGrant{}
+	for _, grant := range getBucketACLResult.Grants {
+		actualGrants = append(actualGrants, bucketACLGrantFromGrant(grant))
+	}
+
+	for _, grant := range actualGrants {
+		if containsBucketACLGrant(expectedACL.Grants, grant) {
+			continue
+		}
+
+		if grant.Grantee == S3AllUsersGroupURI || grant.Grantee == S3AuthenticatedUsersGroupURI {
+			assert.Fail(t, "bucket ACL grants public access", "bucket %s grants %s to %s", bucketName, grant.Permission, grant.Grantee)
+		} else {
+			assert.Fail(t, "unexpected bucket ACL grant", "bucket %s grants %s to %s %s", bucketName, grant.Permission, grant.GranteeType, grant.Grantee)
+		}
+	}
+
+	for _, grant := range expectedACL.Grants {
+		if !containsBucketACLGrant(actualGrants, grant) {
+			assert.Fail(t, "missing bucket ACL grant", "bucket %s does not grant %s to %s %s", bucketName, grant.Permission, grant.GranteeType, grant.Grantee)
+		}
+	}
+}
+
+// bucketACLGrantFromGrant flattens an S3 grant into a BucketACLGrant keyed on the grantee identifier for its type
+func bucketACLGrantFromGrant(grant *s3.Grant) BucketACLGrant {
+	bucketACLGrant := BucketACLGrant{
+		Permission: aws.StringValue(grant.Permission),
+	}
+
+	if grant.Grantee == nil {
+		return bucketACLGrant
+	}
+
+	bucketACLGrant.GranteeType = aws.StringValue(grant.Grantee.Type)
+
+	switch bucketACLGrant.GranteeType {
+	case s3.TypeGroup:
+		bucketACLGrant.Grantee = aws.StringValue(grant.Grantee.URI)
+	default:
+		bucketACLGrant.Grantee = aws.StringValue(grant.Grantee.ID)
+	}
+
+	return bucketACLGrant
+}
+
+// containsBucketACLGrant checks whether a grant is present in a list of grants
+func containsBucketACLGrant(grants []BucketACLGrant, grant BucketACLGrant) bool {
+	for _, g := range grants {
+		if g == grant {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ValidateBucketEncryption get bucket encryption
 func ValidateBucketEncryption(t *testing.T, svc *s3.S3, bucketName string, encryptionType string, verboseOutput bool) {
 	t.Helper()
@@ -328,8 +502,393 @@ func ValidatePublicAccessBlock(t *testing.T, svc *s3.S3, bucketName string, bloc
 		fmt.Println(getPublicAccessBlockResult.String())
 	}
 
-	assert.Equal(t, blockPublicAcls, *getPublicAccessBlockResult.PublicAccessBlockConfiguration.BlockPublicAcls)
-	assert.Equal(t, blockPublicPolicy, *getPublicAccessBlockResult.PublicAccessBlockConfiguration.BlockPublicAcls)
-	assert.Equal(t, ignorePublicAcls, *getPublicAccessBlockResult.PublicAccessBlockConfiguration.BlockPublicAcls)
-	assert.Equal(t, restrictPublicBuckets, *getPublicAccessBlockResult.PublicAccessBlockConfiguration.BlockPublicAcls)
+	assert.Equal(t, blockPublicAcls, aws.BoolValue(getPublicAccessBlockResult.PublicAccessBlockConfiguration.BlockPublicAcls), "BlockPublicAcls")
+	assert.Equal(t, blockPublicPolicy, aws.BoolValue(getPublicAccessBlockResult.PublicAccessBlockConfiguration.BlockPublicPolicy), "BlockPublicPolicy")
+	assert.Equal(t, ignorePublicAcls, aws.BoolValue(getPublicAccessBlockResult.PublicAccessBlockConfiguration.IgnorePublicAcls), "IgnorePublicAcls")
+	assert.Equal(t, restrictPublicBuckets, aws.BoolValue(getPublicAccessBlockResult.PublicAccessBlockConfiguration.RestrictPublicBuckets), "RestrictPublicBuckets")
+}
+
+// ValidateBucketIsNotPublic get bucket PolicyStatus and validates the bucket policy does not make the bucket public
+func ValidateBucketIsNotPublic(t *testing.T, svc *s3.S3, bucketName string, verboseOutput bool) {
+	t.Helper()
+
+	getBucketPolicyStatusInput := &s3.GetBucketPolicyStatusInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	getBucketPolicyStatusResult, err1 := svc.GetBucketPolicyStatus(getBucketPolicyStatusInput)
+	if err1 != nil {
+		if aerr, ok := err1.(awserr.Error); ok {
+			switch aerr.Code() {
+			case "NoSuchBucketPolicy":
+				// a bucket without a policy cannot be made public by its policy
+				if verboseOutput {
+					fmt.Println(aerr.Error())
+				}
+
+				return
+			case s3.ErrCodeNoSuchBucket:
+				fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err1.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(getBucketPolicyStatusResult.String())
+	}
+
+	assert.False(t, aws.BoolValue(getBucketPolicyStatusResult.PolicyStatus.IsPublic), "bucket %s is public", bucketName)
+}
+
+// BucketInventoryConfiguration struct describing an inventory report expected on a bucket.
+// DestinationBucketArn is the ARN of the bucket receiving the reports
+type BucketInventoryConfiguration struct {
+	ID                     string
+	Enabled                bool
+	Prefix                 string
+	DestinationBucketArn   string
+	DestinationPrefix      string
+	Format                 string
+	Frequency              string
+	IncludedObjectVersions string
+	OptionalFields         []string
+}
+
+// ValidateBucketInventoryConfigurations list the bucket inventory configurations and validates each expected configuration by ID
+func ValidateBucketInventoryConfigurations(t *testing.T, svc *s3.S3, bucketName string, expectedConfigurations []BucketInventoryConfiguration, verboseOutput bool) {
+	t.Helper()
+
+	configurations := map[string]*s3.InventoryConfiguration{}
+
+	listInput := &s3.ListBucketInventoryConfigurationsInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	for {
+		listResult, err1 := svc.ListBucketInventoryConfigurations(listInput)
+		if err1 != nil {
+			if aerr, ok := err1.(awserr.Error); ok {
+				switch aerr.Code() {
+				case s3.ErrCodeNoSuchBucket:
+					fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
+				default:
+					fmt.Println(aerr.Error())
+				}
+			} else {
+				fmt.Println(err1.Error())
+			}
+
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		if verboseOutput {
+			fmt.Println(listResult.String())
+		}
+
+		for _, configuration := range listResult.InventoryConfigurationList {
+			configurations[aws.StringValue(configuration.Id)] = configuration
+		}
+
+		if !aws.BoolValue(listResult.IsTruncated) {
+			break
+		}
+
+		listInput.ContinuationToken = listResult.NextContinuationToken
+	}
+
+	for _, expected := range expectedConfigurations {
+		configuration, ok := configurations[expected.ID]
+		if !ok {
+			assert.Fail(t, "missing inventory configuration", "bucket %s has no inventory configuration with ID %s", bucketName, expected.ID)
+
+			continue
+		}
+
+		assert.Equal(t, expected.Enabled, aws.BoolValue(configuration.IsEnabled), "inventory %s enabled", expected.ID)
+		assert.Equal(t, expected.IncludedObjectVersions, aws.StringValue(configuration.IncludedObjectVersions), "inventory %s included object versions", expected.ID)
+		frequency := ""
+		if configuration.Schedule != nil {
+			frequency = aws.StringValue(configuration.Schedule.Frequency)
+		}
+
+		assert.Equal(t, expected.Frequency, frequency, "inventory %s frequency", expected.ID)
+		assert.ElementsMatch(t, expected.OptionalFields, aws.StringValueSlice(configuration.OptionalFields), "inventory %s optional fields", expected.ID)
+
+		prefix := ""
+		if configuration.Filter != nil {
+			prefix = aws.StringValue(configuration.Filter.Prefix)
+		}
+
+		assert.Equal(t, expected.Prefix, prefix, "inventory %s filter prefix", expected.ID)
+
+		if !assert.NotNil(t, configuration.Destination, "inventory %s destination", expected.ID) || !assert.NotNil(t, configuration.Destination.S3BucketDestination, "inventory %s destination bucket", expected.ID) {
+			continue
+		}
+
+		destination := configuration.Destination.S3BucketDestination
+		assert.Equal(t, expected.DestinationBucketArn, aws.StringValue(destination.Bucket), "inventory %s destination bucket", expected.ID)
+		assert.Equal(t, expected.DestinationPrefix, aws.StringValue(destination.Prefix), "inventory %s destination prefix", expected.ID)
+		assert.Equal(t, expected.Format, aws.StringValue(destination.Format), "inventory %s format", expected.ID)
+	}
+}
+
+// BucketIntelligentTieringConfiguration struct describing an intelligent-tiering configuration expected on a bucket.
+// Leave ArchiveAccessDays or DeepArchiveAccessDays at 0 when that tier must not be configured
+type BucketIntelligentTieringConfiguration struct {
+	ID                    string
+	Status                string
+	Prefix                string
+	ArchiveAccessDays     int64
+	DeepArchiveAccessDays int64
+}
+
+// ValidateBucketIntelligentTieringConfigurations list the bucket intelligent-tiering configurations and validates each expected configuration by ID
+func ValidateBucketIntelligentTieringConfigurations(t *testing.T, svc *s3.S3, bucketName string, expectedConfigurations []BucketIntelligentTieringConfiguration, verboseOutput bool) {
+	t.Helper()
+
+	configurations := map[string]*s3.IntelligentTieringConfiguration{}
+
+	listInput := &s3.ListBucketIntelligentTieringConfigurationsInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	for {
+		listResult, err1 := svc.ListBucketIntelligentTieringConfigurations(listInput)
+		if err1 != nil {
+			if aerr, ok := err1.(awserr.Error); ok {
+				switch aerr.Code() {
+				case s3.ErrCodeNoSuchBucket:
+					fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
+				default:
+					fmt.Println(aerr.Error())
+				}
+			} else {
+				fmt.Println(err1.Error())
+			}
+
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		if verboseOutput {
+			fmt.Println(listResult.String())
+		}
+
+		for _, configuration := range listResult.IntelligentTieringConfigurationList {
+			configurations[aws.StringValue(configuration.Id)] = configuration
+		}
+
+		if !aws.BoolValue(listResult.IsTruncated) {
+			break
+		}
+
+		listInput.ContinuationToken = listResult.NextContinuationToken
+	}
+
+	for _, expected := range expectedConfigurations {
+		configuration, ok := configurations[expected.ID]
+		if !ok {
+			assert.Fail(t, "missing intelligent-tiering configuration", "bucket %s has no intelligent-tiering configuration with ID %s", bucketName, expected.ID)
+
+			continue
+		}
+
+		assert.Equal(t, expected.Status, aws.StringValue(configuration.Status), "intelligent-tiering %s status", expected.ID)
+
+		prefix := ""
+		if configuration.Filter != nil {
+			prefix = aws.StringValue(configuration.Filter.Prefix)
+
+			// a prefix combined with tags is nested in the And operator
+			if configuration.Filter.And != nil {
+				prefix = aws.StringValue(configuration.Filter.And.Prefix)
+			}
+		}
+
+		assert.Equal(t, expected.Prefix, prefix, "intelligent-tiering %s filter prefix", expected.ID)
+
+		tieringDays := map[string]int64{}
+		for _, tiering := range configuration.Tierings {
+			tieringDays[aws.StringValue(tiering.AccessTier)] = aws.Int64Value(tiering.Days)
+		}
+
+		assert.Equal(t, expected.ArchiveAccessDays, tieringDays[s3.IntelligentTieringAccessTierArchiveAccess], "intelligent-tiering %s archive access days", expected.ID)
+		assert.Equal(t, expected.DeepArchiveAccessDays, tieringDays[s3.IntelligentTieringAccessTierDeepArchiveAccess], "intelligent-tiering %s deep archive access days", expected.ID)
+	}
+}
+
+// BucketMetricsConfiguration struct describing a request metrics configuration expected on a bucket
+type BucketMetricsConfiguration struct {
+	ID     string
+	Prefix string
+}
+
+// ValidateBucketMetricsConfigurations list the bucket metrics configurations and validates each expected configuration by ID
+func ValidateBucketMetricsConfigurations(t *testing.T, svc *s3.S3, bucketName string, expectedConfigurations []BucketMetricsConfiguration, verboseOutput bool) {
+	t.Helper()
+
+	configurations := map[string]*s3.MetricsConfiguration{}
+
+	listInput := &s3.ListBucketMetricsConfigurationsInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	for {
+		listResult, err1 := svc.ListBucketMetricsConfigurations(listInput)
+		if err1 != nil {
+			if aerr, ok := err1.(awserr.Error); ok {
+				switch aerr.Code() {
+				case s3.ErrCodeNoSuchBucket:
+					fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
+				default:
+					fmt.Println(aerr.Error())
+				}
+			} else {
+				fmt.Println(err1.Error())
+			}
+
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		if verboseOutput {
+			fmt.Println(listResult.String())
+		}
+
+		for _, configuration := range listResult.MetricsConfigurationList {
+			configurations[aws.StringValue(configuration.Id)] = configuration
+		}
+
+		if !aws.BoolValue(listResult.IsTruncated) {
+			break
+		}
+
+		listInput.ContinuationToken = listResult.NextContinuationToken
+	}
+
+	for _, expected := range expectedConfigurations {
+		configuration, ok := configurations[expected.ID]
+		if !ok {
+			assert.Fail(t, "missing metrics configuration", "bucket %s has no metrics configuration with ID %s", bucketName, expected.ID)
+
+			continue
+		}
+
+		prefix := ""
+		if configuration.Filter != nil {
+			prefix = aws.StringValue(configuration.Filter.Prefix)
+
+			// a prefix combined with tags is nested in the And operator
+			if configuration.Filter.And != nil {
+				prefix = aws.StringValue(configuration.Filter.And.Prefix)
+			}
+		}
+
+		assert.Equal(t, expected.Prefix, prefix, "metrics %s filter prefix", expected.ID)
+	}
+}
+
+// BucketAnalyticsConfiguration struct describing a storage class analysis configuration expected on a bucket.
+// Leave DestinationBucketArn empty when the analysis is not exported
+type BucketAnalyticsConfiguration struct {
+	ID                   string
+	Prefix               string
+	DestinationBucketArn string
+	DestinationPrefix    string
+}
+
+// ValidateBucketAnalyticsConfigurations list the bucket analytics configurations and validates each expected configuration by ID
+func ValidateBucketAnalyticsConfigurations(t *testing.T, svc *s3.S3, bucketName string, expectedConfigurations []BucketAnalyticsConfiguration, verboseOutput bool) {
+	t.Helper()
+
+	configurations := map[string]*s3.AnalyticsConfiguration{}
+
+	listInput := &s3.ListBucketAnalyticsConfigurationsInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	for {
+		listResult, err1 := svc.ListBucketAnalyticsConfigurations(listInput)
+		if err1 != nil {
+			if aerr, ok := err1.(awserr.Error); ok {
+				switch aerr.Code() {
+				case s3.ErrCodeNoSuchBucket:
+					fmt.Println(s3.ErrCodeNoSuchBucket, aerr.Error())
+				default:
+					fmt.Println(aerr.Error())
+				}
+			} else {
+				fmt.Println(err1.Error())
+			}
+
+			t.Logf("Failing test.")
+			t.Fail()
+
+			return
+		}
+
+		if verboseOutput {
+			fmt.Println(listResult.String())
+		}
+
+		for _, configuration := range listResult.AnalyticsConfigurationList {
+			configurations[aws.StringValue(configuration.Id)] = configuration
+		}
+
+		if !aws.BoolValue(listResult.IsTruncated) {
+			break
+		}
+
+		listInput.ContinuationToken = listResult.NextContinuationToken
+	}
+
+	for _, expected := range expectedConfigurations {
+		configuration, ok := configurations[expected.ID]
+		if !ok {
+			assert.Fail(t, "missing analytics configuration", "bucket %s has no analytics configuration with ID %s", bucketName, expected.ID)
+
+			continue
+		}
+
+		prefix := ""
+		if configuration.Filter != nil {
+			prefix = aws.StringValue(configuration.Filter.Prefix)
+
+			// a prefix combined with tags is nested in the And operator
+			if configuration.Filter.And != nil {
+				prefix = aws.StringValue(configuration.Filter.And.Prefix)
+			}
+		}
+
+		assert.Equal(t, expected.Prefix, prefix, "analytics %s filter prefix", expected.ID)
+
+		destinationBucketArn := ""
+		destinationPrefix := ""
+
+		if configuration.StorageClassAnalysis != nil && configuration.StorageClassAnalysis.DataExport != nil &&
+			configuration.StorageClassAnalysis.DataExport.Destination != nil && configuration.StorageClassAnalysis.DataExport.Destination.S3BucketDestination != nil {
+			destination := configuration.StorageClassAnalysis.DataExport.Destination.S3BucketDestination
+			destinationBucketArn = aws.StringValue(destination.Bucket)
+			destinationPrefix = aws.StringValue(destination.Prefix)
+		}
+
+		assert.Equal(t, expected.DestinationBucketArn, destinationBucketArn, "analytics %s destination bucket", expected.ID)
+		assert.Equal(t, expected.DestinationPrefix, destinationPrefix, "analytics %s destination prefix", expected.ID)
+	}
 }
@@ -0,0 +1,248 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3control"
+	"github.com/stretchr/testify/assert"
+)
+
+// ValidateAccountPublicAccessBlock get the account level PublicAccessBlock and validates each setting
+func ValidateAccountPublicAccessBlock(t *testing.T, svc *s3control.S3Control, accountID string, blockPublicAcls bool, blockPublicPolicy bool, ignorePublicAcls bool, restrictPublicBuckets bool, verboseOutput bool) {
+	t.Helper()
+
+	getPublicAccessBlockInput := &s3control.GetPublicAccessBlockInput{
+		AccountId: aws.String(accountID),
+	}
+
+	getPublicAccessBlockResult, err := svc.GetPublicAccessBlock(getPublicAccessBlockInput)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case s3control.ErrCodeNoSuchPublicAccessBlockConfiguration:
+				fmt.Println(s3control.ErrCodeNoSuchPublicAccessBlockConfiguration, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(getPublicAccessBlockResult.String())
+	}
+
+	assert.Equal(t, blockPublicAcls, aws.BoolValue(getPublicAccessBlockResult.PublicAccessBlockConfiguration.BlockPublicAcls), "BlockPublicAcls")
+	assert.Equal(t, blockPublicPolicy, aws.BoolValue(getPublicAccessBlockResult.PublicAccessBlockConfiguration.BlockPublicPolicy), "BlockPublicPolicy")
+	assert.Equal(t, ignorePublicAcls, aws.BoolValue(getPublicAccessBlockResult.PublicAccessBlockConfiguration.IgnorePublicAcls), "IgnorePublicAcls")
+	assert.Equal(t, restrictPublicBuckets, aws.BoolValue(getPublicAccessBlockResult.PublicAccessBlockConfiguration.RestrictPublicBuckets), "RestrictPublicBuckets")
+}
+
+// AccessPoint struct containing elements returned from an S3 access point module.
+// Leave VpcID empty for an access point with an Internet network origin
+type AccessPoint struct {
+	Name                  string
+	Bucket                string
+	VpcID                 string
+	BlockPublicAcls       bool
+	BlockPublicPolicy     bool
+	IgnorePublicAcls      bool
+	RestrictPublicBuckets bool
+}
+
+// ValidateAccessPoint get the access point and validates its bucket, network origin and public access block settings
+func ValidateAccessPoint(t *testing.T, svc *s3control.S3Control, accountID string, accessPoint AccessPoint, verboseOutput bool) {
+	t.Helper()
+
+	getAccessPointInput := &s3control.GetAccessPointInput{
+		AccountId: aws.String(accountID),
+		Name:      aws.String(accessPoint.Name),
+	}
+
+	getAccessPointResult, err := svc.GetAccessPoint(getAccessPointInput)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case s3control.ErrCodeNotFoundException:
+				fmt.Println(s3control.ErrCodeNotFoundException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(getAccessPointResult.String())
+	}
+
+	assert.Equal(t, accessPoint.Name, aws.StringValue(getAccessPointResult.Name))
+	assert.Equal(t, accessPoint.Bucket, aws.StringValue(getAccessPointResult.Bucket))
+
+	if accessPoint.VpcID != "" {
+		assert.Equal(t, s3control.NetworkOriginVpc, aws.StringValue(getAccessPointResult.NetworkOrigin))
+
+		if assert.NotNil(t, getAccessPointResult.VpcConfiguration) {
+			assert.Equal(t, accessPoint.VpcID, aws.StringValue(getAccessPointResult.VpcConfiguration.VpcId))
+		}
+	} else {
+		assert.Equal(t, s3control.NetworkOriginInternet, aws.StringValue(getAccessPointResult.NetworkOrigin))
+	}
+
+	if assert.NotNil(t, getAccessPointResult.PublicAccessBlockConfiguration) {
+		assert.Equal(t, accessPoint.BlockPublicAcls, aws.BoolValue(getAccessPointResult.PublicAccessBlockConfiguration.BlockPublicAcls), "BlockPublicAcls")
+		assert.Equal(t, accessPoint.BlockPublicPolicy, aws.BoolValue(getAccessPointResult.PublicAccessBlockConfiguration.BlockPublicPolicy), "BlockPublicPolicy")
+		assert.Equal(t, accessPoint.IgnorePublicAcls, aws.BoolValue(getAccessPointResult.PublicAccessBlockConfiguration.IgnorePublicAcls), "IgnorePublicAcls")
+		assert.Equal(t, accessPoint.RestrictPublicBuckets, aws.BoolValue(getAccessPointResult.PublicAccessBlockConfiguration.RestrictPublicBuckets), "RestrictPublicBuckets")
+	}
+}
+
+// ValidateAccessPointPolicy get the access point policy and validates it grants the same permissions as the expected JSON
+func ValidateAccessPointPolicy(t *testing.T, svc *s3control.S3Control, accountID string, accessPointName string, policyJSON string, verboseOutput bool) {
+	t.Helper()
+
+	getAccessPointPolicyInput := &s3control.GetAccessPointPolicyInput{
+		AccountId: aws.String(accountID),
+		Name:      aws.String(accessPointName),
+	}
+
+	getAccessPointPolicyResult, err := svc.GetAccessPointPolicy(getAccessPointPolicyInput)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case s3control.ErrCodeNotFoundException:
+				fmt.Println(s3control.ErrCodeNotFoundException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(getAccessPointPolicyResult.String())
+	}
+
+	ValidatePolicyDocumentsEquivalent(t, policyJSON, aws.StringValue(getAccessPointPolicyResult.Policy), verboseOutput)
+}
+
+// ValidateMultiRegionAccessPoint get the multi-region access point and validates its status and the buckets behind it.
+// Multi-region access point requests are routed through us-west-2, so svc must be created for that region
+func ValidateMultiRegionAccessPoint(t *testing.T, svc *s3control.S3Control, accountID string, accessPointName string, status string, buckets []string, verboseOutput bool) {
+	t.Helper()
+
+	getMultiRegionAccessPointInput := &s3control.GetMultiRegionAccessPointInput{
+		AccountId: aws.String(accountID),
+		Name:      aws.String(accessPointName),
+	}
+
+	getMultiRegionAccessPointResult, err := svc.GetMultiRegionAccessPoint(getMultiRegionAccessPointInput)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case s3control.ErrCodeNotFoundException:
+				fmt.Println(s3control.ErrCodeNotFoundException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(getMultiRegionAccessPointResult.String())
+	}
+
+	if !assert.NotNil(t, getMultiRegionAccessPointResult.AccessPoint, "multi-region access point %s", accessPointName) {
+		return
+	}
+
+	resultBuckets := []string{}
+	for _, region := range getMultiRegionAccessPointResult.AccessPoint.Regions {
+		resultBuckets = append(resultBuckets, aws.StringValue(region.Bucket))
+	}
+
+	assert.Equal(t, accessPointName, aws.StringValue(getMultiRegionAccessPointResult.AccessPoint.Name))
+	assert.Equal(t, status, aws.StringValue(getMultiRegionAccessPointResult.AccessPoint.Status))
+	assert.ElementsMatch(t, buckets, resultBuckets)
+}
+
+// ValidateObjectLambdaAccessPoint get the Object Lambda access point configuration and validates its supporting access point and transformation Lambda
+func ValidateObjectLambdaAccessPoint(t *testing.T, svc *s3control.S3Control, accountID string, accessPointName string, supportingAccessPointArn string, lambdaArn string, verboseOutput bool) {
+	t.Helper()
+
+	getConfigurationInput := &s3control.GetAccessPointConfigurationForObjectLambdaInput{
+		AccountId: aws.String(accountID),
+		Name:      aws.String(accessPointName),
+	}
+
+	getConfigurationResult, err := svc.GetAccessPointConfigurationForObjectLambda(getConfigurationInput)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case s3control.ErrCodeNotFoundException:
+				fmt.Println(s3control.ErrCodeNotFoundException, aerr.Error())
+			default:
+				fmt.Println(aerr.Error())
+			}
+		} else {
+			fmt.Println(err.Error())
+		}
+
+		t.Logf("Failing test.")
+		t.Fail()
+
+		return
+	}
+
+	if verboseOutput {
+		fmt.Println(getConfigurationResult.String())
+	}
+
+	if !assert.NotNil(t, getConfigurationResult.Configuration, "configuration of Object Lambda access point %s", accessPointName) {
+		return
+	}
+
+	assert.Equal(t, supportingAccessPointArn, aws.StringValue(getConfigurationResult.Configuration.SupportingAccessPoint))
+
+	functionArns := []string{}
+
+	for _, transformation := range getConfigurationResult.Configuration.TransformationConfigurations {
+		if transformation.ContentTransformation != nil && transformation.ContentTransformation.AwsLambda != nil {
+			functionArns = append(functionArns, aws.StringValue(transformation.ContentTransformation.AwsLambda.FunctionArn))
+		}
+	}
+
+	assert.NotEmpty(t, functionArns)
+
+	for _, functionArn := range functionArns {
+		assert.Equal(t, lambdaArn, functionArn)
+	}
+}